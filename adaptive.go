@@ -0,0 +1,76 @@
+package ratelimiter
+
+const (
+	// adaptiveDecrease is what the adaptive factor is multiplied by on each unhealthy report
+	adaptiveDecrease = 0.5
+
+	// adaptiveIncrease is what's added back to the adaptive factor on each healthy report
+	adaptiveIncrease = 0.05
+)
+
+// adaptiveState is the AIMD controller behind WithAdaptiveLimit
+type adaptiveState struct {
+	threshold float64
+	minFactor float64
+	factor    float64
+}
+
+// WithAdaptiveLimit makes every limit the cache checks follow the health of whatever it protects,
+// as reported with ReportHealth. Each report under threshold halves the limits, down to minFactor
+// of what they'd otherwise be, and each report at or over it adds back 5% until they're whole again.
+// Health scores are up to the caller, e.g. 1 for healthy down to 0 for failing.
+func WithAdaptiveLimit(threshold, minFactor float64) Option {
+	return func(c *Cache) {
+		if minFactor <= 0 {
+			minFactor = adaptiveIncrease
+		}
+		if minFactor > 1 {
+			minFactor = 1
+		}
+		c.adaptive = &adaptiveState{threshold: threshold, minFactor: minFactor, factor: 1}
+	}
+}
+
+// ReportHealth feeds a downstream health score to the cache's adaptive limit, see WithAdaptiveLimit.
+// It does nothing if the cache wasn't created WithAdaptiveLimit.
+func (c *Cache) ReportHealth(score float64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	a := c.adaptive
+	if a == nil || c.closed {
+		return
+	}
+	if score < a.threshold {
+		a.factor *= adaptiveDecrease
+		if a.factor < a.minFactor {
+			a.factor = a.minFactor
+		}
+	} else {
+		a.factor += adaptiveIncrease
+		if a.factor > 1 {
+			a.factor = 1
+		}
+	}
+}
+
+// AdaptiveLimit returns the limit an Incr passed maxValue is held to right now, after the adjustment
+// for downstream health. Without WithAdaptiveLimit it's always maxValue.
+func (c *Cache) AdaptiveLimit(maxValue int) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.adaptiveLimit(maxValue)
+}
+
+// adaptiveLimit is AdaptiveLimit for callers that already hold the lock, it never tightens
+// a positive limit below 1
+func (c *Cache) adaptiveLimit(maxValue int) int {
+	if c.adaptive == nil || maxValue <= 0 {
+		return maxValue
+	}
+	if limit := int(float64(maxValue) * c.adaptive.factor); limit > 0 {
+		return limit
+	}
+	return 1
+}
@@ -0,0 +1,54 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimit(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithAdaptiveLimit(0.5, 0.1))
+
+	if limit := rl.AdaptiveLimit(100); limit != 100 {
+		t.Fatalf("expected the full limit while healthy, got [%d]", limit)
+	}
+
+	// stress halves the limit each time down to the floor
+	rl.ReportHealth(0.2)
+	if limit := rl.AdaptiveLimit(100); limit != 50 {
+		t.Fatalf("expected the limit to halve under stress, got [%d]", limit)
+	}
+	for i := 0; i < 5; i++ {
+		rl.ReportHealth(0)
+	}
+	if limit := rl.AdaptiveLimit(100); limit != 10 {
+		t.Fatalf("expected the limit to stop at the floor of [10], got [%d]", limit)
+	}
+
+	// Incr is held to it
+	for i := 0; i < 10; i++ {
+		rl.Incr("key", 100)
+	}
+	if _, ok := rl.Incr("key", 100); ok {
+		t.Fatalf("expected Incr to be held to the adaptive limit")
+	}
+
+	// and it recovers a step at a time once healthy
+	rl.ReportHealth(0.9)
+	if limit := rl.AdaptiveLimit(100); limit != 15 {
+		t.Fatalf("expected the limit to recover additively, got [%d]", limit)
+	}
+	for i := 0; i < 50; i++ {
+		rl.ReportHealth(1)
+	}
+	if limit := rl.AdaptiveLimit(100); limit != 100 {
+		t.Fatalf("expected the limit to recover fully, got [%d]", limit)
+	}
+}
+
+func TestAdaptiveLimitDisabled(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.ReportHealth(0)
+	if limit := rl.AdaptiveLimit(100); limit != 100 {
+		t.Fatalf("expected no adjustment without WithAdaptiveLimit, got [%d]", limit)
+	}
+}
@@ -0,0 +1,84 @@
+package ratelimiter
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultAnomalyFactor is used when Cache.AnomalyFactor isn't set
+	DefaultAnomalyFactor = 3.0
+
+	// anomalyMinPeriods is how many rate periods a key needs behind it before
+	// it has a baseline worth comparing against
+	anomalyMinPeriods = 3
+)
+
+// Anomaly describes a key whose count in the current rate period jumped well above its baseline.
+type Anomaly struct {
+	Key interface{}
+
+	// Count is the key's count so far in the current rate period
+	Count uint64
+
+	// Baseline is the key's usual count per rate period, an exponentially weighted
+	// moving average of its previous periods using Cache.EWMAWeight
+	Baseline float64
+
+	// WindowStart is when the current rate period began
+	WindowStart time.Time
+}
+
+// anomalyState tracks fixed ratePeriod long windows for a key independently of the rate limit
+// window, which only resets once a key goes over its limit
+type anomalyState struct {
+	baseline float64
+	periods  int
+	count    uint64
+	window   time.Time
+	fired    bool
+}
+
+// observeAnomaly counts an increment towards the entry's current window, folding finished windows
+// into its baseline and calling OnAnomaly the first time a window goes over AnomalyFactor times it
+func (c *Cache) observeAnomaly(e *entry) {
+	if c.ratePeriod <= 0 {
+		return
+	}
+
+	a := &e.anomaly
+	now := c.now()
+	if a.window.IsZero() {
+		a.window = c.windowStart(e.id, now)
+	}
+
+	if periods := int64(now.Sub(a.window) / c.ratePeriod); periods > 0 {
+		weight := c.ewmaWeight()
+		if a.periods == 0 {
+			a.baseline = float64(a.count)
+		} else {
+			a.baseline = weight*float64(a.count) + (1-weight)*a.baseline
+		}
+		a.baseline *= math.Pow(1-weight, float64(periods-1))
+		a.periods += int(periods)
+		a.count = 0
+		a.fired = false
+		a.window = a.window.Add(c.ratePeriod * time.Duration(periods))
+	}
+
+	a.count++
+	if a.fired || a.periods < anomalyMinPeriods {
+		return
+	}
+
+	factor := c.AnomalyFactor
+	if factor <= 0 {
+		factor = DefaultAnomalyFactor
+	}
+	// a key that's been nearly silent still needs a handful of hits to count as a spike
+	if float64(a.count) > factor*math.Max(a.baseline, 1) {
+		a.fired = true
+		fn, anomaly := c.OnAnomaly, Anomaly{Key: e.key, Count: a.count, Baseline: a.baseline, WindowStart: a.window}
+		c.lock.after(func() { fn(anomaly) })
+	}
+}
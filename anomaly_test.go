@@ -0,0 +1,65 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnomalyCallback(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(100, time.Second)
+	rl.now = func() time.Time { return now }
+
+	var anomalies []Anomaly
+	rl.OnAnomaly = func(a Anomaly) {
+		anomalies = append(anomalies, a)
+	}
+
+	key := "foo"
+	// establish a baseline of 5 per period
+	for p := 0; p < 5; p++ {
+		for i := 0; i < 5; i++ {
+			_, _ = rl.Incr(key, 1000)
+		}
+		now = now.Add(time.Second)
+	}
+	if len(anomalies) != 0 {
+		t.Fatalf("expected steady traffic not to be flagged, got [%d] anomalies", len(anomalies))
+	}
+
+	// three times the baseline is 15, so the 16th increment is the spike
+	for i := 0; i < 30; i++ {
+		_, _ = rl.Incr(key, 1000)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("expected exactly [1] anomaly for the spiking period, got [%d]", len(anomalies))
+	}
+
+	a := anomalies[0]
+	if a.Key.(string) != key {
+		t.Fatalf("expected anomaly for [%s] got [%v]", key, a.Key)
+	}
+	if a.Count != 16 {
+		t.Fatalf("expected the anomaly to fire at count [16] but it fired at [%d]", a.Count)
+	}
+	if a.Baseline < 4.9 || a.Baseline > 5.1 {
+		t.Fatalf("expected a baseline of about [5] got [%f]", a.Baseline)
+	}
+	if !a.WindowStart.Equal(now) {
+		t.Fatalf("expected the anomaly window to start at [%s] got [%s]", now, a.WindowStart)
+	}
+}
+
+// a brand new key has no baseline yet so it can't be anomalous
+func TestAnomalyNeedsBaseline(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(100, time.Second)
+	rl.now = func() time.Time { return now }
+	rl.OnAnomaly = func(a Anomaly) {
+		t.Fatalf("expected no anomaly without a baseline, got count [%d]", a.Count)
+	}
+
+	for i := 0; i < 100; i++ {
+		_, _ = rl.Incr("foo", 1000)
+	}
+}
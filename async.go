@@ -0,0 +1,113 @@
+package ratelimiter
+
+import "sync"
+
+// evicted is a single OnEvicted call waiting to be delivered
+type evicted struct {
+	fn         func(key interface{}, value interface{})
+	key, value interface{}
+}
+
+// evictQueue is a bounded queue of OnEvicted calls drained by a fixed pool of workers
+type evictQueue struct {
+	ch chan evicted
+	wg sync.WaitGroup
+
+	// senders counts calls taken from a cacheLock that haven't been queued yet
+	senders sync.WaitGroup
+}
+
+// cacheLock is the cache's RWMutex. Callbacks due while it's held are kept aside and only run by
+// Unlock once the lock is released, in the order they came up, so a slow callback doesn't hold up
+// everything else waiting on the lock and one that calls back into the cache doesn't deadlock. That
+// includes queueing async evictions, since a full queue blocks until the workers catch up and the
+// workers may be waiting on the lock themselves.
+type cacheLock struct {
+	sync.RWMutex
+	pending []func()
+}
+
+// after runs fn once the write lock is released, must be called with the write lock held
+func (l *cacheLock) after(fn func()) {
+	l.pending = append(l.pending, fn)
+}
+
+// hold keeps an OnEvicted call until the lock is released, must be called with the write lock held
+func (l *cacheLock) hold(q *evictQueue, fn func(key interface{}, value interface{}), key, value interface{}) {
+	q.senders.Add(1)
+	ev := evicted{fn, key, value}
+	l.after(func() {
+		q.send(ev)
+		q.senders.Done()
+	})
+}
+
+// Unlock releases the write lock then runs the callbacks that came up while it was held
+func (l *cacheLock) Unlock() {
+	pending := l.pending
+	l.pending = nil
+	l.RWMutex.Unlock()
+	for _, fn := range pending {
+		fn()
+	}
+}
+
+func newEvictQueue(workers, queueSize int) *evictQueue {
+	q := &evictQueue{ch: make(chan evicted, queueSize)}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer q.wg.Done()
+			for ev := range q.ch {
+				ev.fn(ev.key, ev.value)
+			}
+		}()
+	}
+	return q
+}
+
+// send queues a callback, blocking once the queue is full so nothing is dropped
+func (q *evictQueue) send(ev evicted) {
+	q.ch <- ev
+}
+
+// stop delivers everything still queued then waits for the workers to exit
+func (q *evictQueue) stop() {
+	q.senders.Wait()
+	close(q.ch)
+	q.wg.Wait()
+}
+
+// WithAsyncEviction calls OnEvicted from a pool of worker goroutines instead of inline, so a slow
+// callback doesn't hold up Incr and everything else waiting on the cache lock. Up to queueSize calls
+// are buffered, once the buffer is full evictions block until the workers catch up so every eviction
+// is still delivered, the wait happens after the cache lock is released. With more than one worker
+// callbacks can arrive in a different order than the evictions happened. Since callbacks run without
+// the cache locked they're free to call back into it. Call StopAsyncEviction once the cache is no
+// longer needed to deliver what's queued and stop the workers.
+func WithAsyncEviction(workers, queueSize int) Option {
+	return func(c *Cache) {
+		if workers <= 0 {
+			return
+		}
+		if queueSize < 0 {
+			queueSize = 0
+		}
+		c.evictQueue = newEvictQueue(workers, queueSize)
+	}
+}
+
+// StopAsyncEviction waits for every queued OnEvicted call to be delivered, stops the workers started
+// by WithAsyncEviction and goes back to calling OnEvicted inline. It's safe to call on a cache without
+// async eviction.
+func (c *Cache) StopAsyncEviction() {
+	c.lock.Lock()
+	q := c.evictQueue
+	c.evictQueue = nil
+	c.lock.Unlock()
+
+	// callbacks may call back into the cache, so wait for them without holding the lock
+	if q != nil {
+		q.stop()
+	}
+}
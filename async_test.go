@@ -0,0 +1,117 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncEviction(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithAsyncEviction(4, 100))
+
+	var lock sync.Mutex
+	delivered := map[interface{}]bool{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		delivered[key] = true
+		lock.Unlock()
+	}
+
+	// 100 evictions with a 10ms callback would take a second inline
+	start := time.Now()
+	for i := 0; i < 110; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected evictions not to wait on a slow callback, took [%s]", elapsed)
+	}
+
+	rl.StopAsyncEviction()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(delivered) != 100 {
+		t.Fatalf("expected all [100] evictions to be delivered, got [%d]", len(delivered))
+	}
+	for i := 0; i < 100; i++ {
+		if key := fmt.Sprintf("foo_%d", i); !delivered[key] {
+			t.Fatalf("expected eviction of %s to be delivered", key)
+		}
+	}
+}
+
+// a full queue blocks rather than dropping anything
+func TestAsyncEvictionBackpressure(t *testing.T) {
+	rl, _ := New(1, time.Hour, WithAsyncEviction(1, 1))
+
+	var lock sync.Mutex
+	var order []interface{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		order = append(order, key)
+		lock.Unlock()
+	}
+
+	for i := 0; i < 20; i++ {
+		_, _ = rl.Incr(i, 10)
+	}
+	rl.StopAsyncEviction()
+
+	// a single worker keeps eviction order
+	if len(order) != 19 {
+		t.Fatalf("expected [19] evictions delivered, got [%d]", len(order))
+	}
+	for i, key := range order {
+		if key != i {
+			t.Fatalf("expected a single worker to deliver in order, got %v", order)
+		}
+	}
+
+	// once stopped callbacks are inline again
+	rl.StopAsyncEviction()
+	_, _ = rl.Incr("foo", 10)
+	if len(order) != 20 {
+		t.Fatalf("expected an inline callback after stopping, got [%d] evictions", len(order))
+	}
+}
+
+// a callback that calls back into the cache doesn't deadlock a removal that overflows the queue
+func TestAsyncEvictionReentrant(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithAsyncEviction(1, 1))
+
+	var lock sync.Mutex
+	var delivered int
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		_ = rl.Len()
+		lock.Lock()
+		delivered++
+		lock.Unlock()
+	}
+
+	for i := 0; i < 5; i++ {
+		_, _ = rl.IncrInGroup(i, "group", 10)
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- rl.RemoveGroup("group")
+	}()
+	select {
+	case removed := <-done:
+		if removed != 5 {
+			t.Fatalf("expected [5] keys removed, got [%d]", removed)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected RemoveGroup not to deadlock on a full queue")
+	}
+
+	rl.StopAsyncEviction()
+	lock.Lock()
+	defer lock.Unlock()
+	if delivered != 5 {
+		t.Fatalf("expected all [5] evictions to be delivered, got [%d]", delivered)
+	}
+}
@@ -0,0 +1,20 @@
+package ratelimiter
+
+import "time"
+
+// AuditRecord describes a finished rate period for a key, it's what OnAudit is called with when
+// the period rolls over. Records are only produced when the key is next incremented, so ResetAt
+// can be well after End for a key that went quiet.
+type AuditRecord struct {
+	Key interface{}
+
+	// Count is what the key's count was when the period finished
+	Count uint64
+
+	// Start and End are the bounds of the finished period
+	Start time.Time
+	End   time.Time
+
+	// ResetAt is when the increment that started the next period happened
+	ResetAt time.Time
+}
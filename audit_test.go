@@ -0,0 +1,83 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnAudit(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	var records []AuditRecord
+	rl.OnAudit = func(r AuditRecord) {
+		records = append(records, r)
+	}
+
+	start := clock.Now()
+	for i := 0; i < 3; i++ {
+		rl.Incr("key", 3)
+	}
+	if len(records) != 0 {
+		t.Fatalf("expected no records while the period is running, have [%d]", len(records))
+	}
+
+	// the count has to go over the limit once the period is up for it to roll over
+	clock.Add(11 * time.Second)
+	resetAt := clock.Now()
+	rl.Incr("key", 3)
+	if len(records) != 1 {
+		t.Fatalf("expected a record for the reset, have [%d]", len(records))
+	}
+	r := records[0]
+	if r.Key != "key" || r.Count != 3 {
+		t.Fatalf("expected a record for key with a count of 3, have [%v] [%d]", r.Key, r.Count)
+	}
+	if !r.Start.Equal(start) || !r.End.Equal(start.Add(10*time.Second)) || !r.ResetAt.Equal(resetAt) {
+		t.Fatalf("unexpected bounds for the finished period [%v] [%v] [%v]", r.Start, r.End, r.ResetAt)
+	}
+
+	// each further reset gets its own record, starting where the last one reset
+	rl.Incr("key", 1)
+	clock.Add(11 * time.Second)
+	rl.Incr("key", 1)
+	if len(records) != 2 {
+		t.Fatalf("expected a record for each reset, have [%d]", len(records))
+	}
+	if r := records[1]; r.Count != 2 || !r.Start.Equal(resetAt) {
+		t.Fatalf("expected the second record to count [2] from [%v], have [%d] from [%v]", resetAt, r.Count, r.Start)
+	}
+}
+
+// the callbacks run after the lock is released, so they can call back into the cache
+func TestCallbacksReentrant(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	var audited, violated bool
+	rl.OnAudit = func(r AuditRecord) {
+		_, audited = rl.Peek(r.Key)
+	}
+	rl.OnViolation = func(v Violation) {
+		_, violated = rl.Peek(v.Key)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rl.Incr("foo", 1)
+		rl.Incr("foo", 1)
+		clock.Add(11 * time.Second)
+		rl.Incr("foo", 1)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected callbacks calling back into the cache not to deadlock")
+	}
+	if !audited || !violated {
+		t.Fatalf("expected both callbacks to see the key, audit [%t] violation [%t]", audited, violated)
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimiter
+
+import "time"
+
+// closedChan is handed out when a key is already available
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// AvailableAfter returns a channel that's closed once key's current rate period lifts, so
+// a goroutine can select on it rather than polling Incr. The channel is closed right away if
+// the key isn't cached or its rate period is already over, and early if the key is removed,
+// evicted or reset in the meantime since it's available again at that point.
+// With a ratePeriod of 0 the rate limit never lifts, so the channel is only closed by one of those.
+func (c *Cache) AvailableAfter(key interface{}) <-chan struct{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return closedChan
+	}
+	ent, ok := c.find(key)
+	if !ok {
+		return closedChan
+	}
+	e := ent.Value.(*entry)
+	if e.available != nil {
+		return e.available
+	}
+
+	var wait time.Duration
+	if c.ratePeriod > 0 {
+		wait = c.windowEnd(e).Sub(c.now())
+		if wait <= 0 {
+			return closedChan
+		}
+	}
+
+	e.available = make(chan struct{})
+	if c.ratePeriod > 0 {
+		e.availableTimer = time.AfterFunc(wait, func() {
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			c.notifyAvailable(e)
+		})
+	}
+	return e.available
+}
+
+// notifyAvailable closes an entry's AvailableAfter channel if it has one and stops its timer
+func (c *Cache) notifyAvailable(e *entry) {
+	if e.available == nil {
+		return
+	}
+	if e.availableTimer != nil {
+		e.availableTimer.Stop()
+		e.availableTimer = nil
+	}
+	close(e.available)
+	e.available = nil
+}
@@ -0,0 +1,67 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAvailableAfter(t *testing.T) {
+	ratePeriod := 200 * time.Millisecond
+	rl, _ := New(10, ratePeriod)
+
+	key := "foo"
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, _ = rl.Incr(key, 2)
+	}
+
+	available := rl.AvailableAfter(key)
+	if rl.AvailableAfter(key) != available {
+		t.Fatalf("expected callers waiting on the same key to share a channel")
+	}
+
+	select {
+	case <-available:
+		waited := time.Since(start)
+		if waited < ratePeriod-20*time.Millisecond {
+			t.Fatalf("expected the channel to close around [%s] but it closed after [%s]", ratePeriod, waited)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the channel to close once the rate period lifted")
+	}
+
+	if _, underRateLimit := rl.Incr(key, 2); !underRateLimit {
+		t.Fatalf("expected foo to be under the rate limit once the channel closed")
+	}
+}
+
+func TestAvailableAfterMissingKey(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	select {
+	case <-rl.AvailableAfter("foo"):
+	default:
+		t.Fatalf("expected an uncached key to be available immediately")
+	}
+}
+
+func TestAvailableAfterCancelledOnRemove(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	key := "foo"
+	_, _ = rl.Incr(key, 1)
+	available := rl.AvailableAfter(key)
+
+	rl.Remove(key)
+	select {
+	case <-available:
+	case <-time.After(time.Second):
+		t.Fatalf("expected removing the key to close its channel")
+	}
+
+	// the entry's timer should be gone along with it
+	_, _ = rl.Incr(key, 1)
+	if e := rl.cache[key].Value.(*entry); e.available != nil || e.availableTimer != nil {
+		t.Fatalf("expected a re-added key to start without a pending channel")
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimiter
+
+// IncrBanked increments key like Incr, except allowance left unused in quiet rate periods is saved
+// as credit that a later period can spend to go over maxValue. When a period ends with a count under
+// maxValue the difference goes into the key's bank, as does a full maxValue for every period that
+// passes with no increments at all, up to bankCap. An increment over maxValue is allowed as long as
+// there's credit to spend, one credit per increment. The return values are the same as Incr.
+// Unlike Incr, a new period starts as soon as the old one is over rather than once the key goes over
+// its limit, otherwise there'd be no quiet periods to bank. Banking needs a positive ratePeriod.
+func (c *Cache) IncrBanked(key interface{}, maxValue, bankCap int) (uint64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return 0, false
+	}
+
+	_, existed := c.find(key)
+	e, ok := c.admit(key)
+	if !ok {
+		return 0, false
+	}
+	if existed {
+		c.bankUnused(e, key, maxValue, bankCap)
+	}
+
+	e.value++
+	e.total++
+	if e.value <= uint64(maxValue) {
+		return e.value, true
+	}
+	if e.bank > 0 {
+		e.bank--
+		return e.value, true
+	}
+	return e.value, false
+}
+
+// bankUnused starts a new rate period for the entry if its current one is over, saving what the
+// finished periods didn't use as credit
+func (c *Cache) bankUnused(e *entry, key interface{}, maxValue, bankCap int) {
+	if c.ratePeriod <= 0 {
+		return
+	}
+	now := c.now()
+	if !c.expired(e, now) {
+		return
+	}
+
+	if e.value < uint64(maxValue) {
+		e.bank += uint64(maxValue) - e.value
+	}
+	// any further whole periods that went by had no increments at all
+	if idle := uint64(now.Sub(e.updated)/c.ratePeriod) - 1; idle > 0 {
+		e.bank += idle * uint64(maxValue)
+	}
+	if e.bank > uint64(bankCap) {
+		e.bank = uint64(bankCap)
+	}
+
+	c.rollover(e, key, e.value, now)
+	e.value = 0
+}
@@ -0,0 +1,91 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// countBankedAllowed increments key until it's limited and returns how many were allowed
+func countBankedAllowed(rl *Cache, key interface{}, maxValue, bankCap int) int {
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		if _, ok := rl.IncrBanked(key, maxValue, bankCap); !ok {
+			break
+		}
+		allowed++
+	}
+	return allowed
+}
+
+func TestIncrBanked(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	key := "foo"
+	maxCount, bankCap := 10, 15
+
+	// a busy first period gets exactly the limit, there's nothing banked yet
+	if allowed := countBankedAllowed(rl, key, maxCount, bankCap); allowed != maxCount {
+		t.Fatalf("expected [%d] allowed with an empty bank, got [%d]", maxCount, allowed)
+	}
+
+	// a quiet period that only uses 4 banks the other 6
+	clock.Add(11 * time.Second)
+	for i := 0; i < 4; i++ {
+		_, _ = rl.IncrBanked(key, maxCount, bankCap)
+	}
+
+	// the next period can burst to its limit plus the 6 credits
+	clock.Add(11 * time.Second)
+	if allowed := countBankedAllowed(rl, key, maxCount, bankCap); allowed != maxCount+6 {
+		t.Fatalf("expected [%d] allowed spending saved credit, got [%d]", maxCount+6, allowed)
+	}
+
+	// the credit has been spent so the following period is back to the plain limit
+	clock.Add(11 * time.Second)
+	if allowed := countBankedAllowed(rl, key, maxCount, bankCap); allowed != maxCount {
+		t.Fatalf("expected [%d] allowed once the bank was depleted, got [%d]", maxCount, allowed)
+	}
+}
+
+func TestIncrBankedCap(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	key := "foo"
+	maxCount, bankCap := 10, 15
+
+	_, _ = rl.IncrBanked(key, maxCount, bankCap)
+
+	// five idle periods would bank far more than the cap
+	clock.Add(55 * time.Second)
+	if allowed := countBankedAllowed(rl, key, maxCount, bankCap); allowed != maxCount+bankCap {
+		t.Fatalf("expected the bank to be capped at [%d] credits, got [%d] allowed", bankCap, allowed)
+	}
+}
+
+// a banked period rolling over is reported like any other and drops merged counts
+func TestIncrBankedRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	var previous uint64
+	rl.SetResetHandler("foo", func(p uint64) {
+		previous = p
+	})
+	rl.IncrBanked("foo", 10, 10)
+	rl.IncrBanked("foo", 10, 10)
+	rl.MergeCRDT("node-b", []KeyCount{{"foo", 4}})
+
+	clock.Add(11 * time.Second)
+	rl.IncrBanked("foo", 10, 10)
+	if previous != 2 || rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be reported with the finished count, got [%d]", previous)
+	}
+	if count, _ := rl.MergedCount("foo"); count != 1 {
+		t.Fatalf("expected the merged counts to go with the old period, got [%d]", count)
+	}
+}
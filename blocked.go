@@ -0,0 +1,72 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"time"
+)
+
+// BlockedKey is a key RecentlyBlocked reports.
+type BlockedKey struct {
+	Key interface{}
+
+	// Count is the key's count the last time it was denied
+	Count uint64
+
+	// At is when it was last denied
+	At time.Time
+}
+
+// blockedList is a small LRU of the keys most recently denied by Incr, kept apart from the cache so
+// it outlives the keys' own entries
+type blockedList struct {
+	size  int
+	order *list.List
+	keys  map[interface{}]*list.Element
+}
+
+// add records that the key stored under id was blocked, moving it to the front if it was already there
+func (b *blockedList) add(id interface{}, bk BlockedKey) {
+	if ent, ok := b.keys[id]; ok {
+		b.order.MoveToFront(ent)
+		ent.Value.(*blockedKey).bk = bk
+		return
+	}
+	if b.order.Len() >= b.size {
+		oldest := b.order.Back()
+		b.order.Remove(oldest)
+		delete(b.keys, oldest.Value.(*blockedKey).id)
+	}
+	b.keys[id] = b.order.PushFront(&blockedKey{id, bk})
+}
+
+type blockedKey struct {
+	id interface{}
+	bk BlockedKey
+}
+
+// WithBlockedHistory keeps the last size distinct keys Incr denied for being over the rate limit,
+// for RecentlyBlocked. The history is separate from the cache, so a key stays in it after its entry
+// is evicted and only leaves once size other keys have been blocked since.
+func WithBlockedHistory(size int) Option {
+	return func(c *Cache) {
+		if size > 0 {
+			c.blocked = &blockedList{size: size, order: list.New(), keys: make(map[interface{}]*list.Element)}
+		}
+	}
+}
+
+// RecentlyBlocked returns up to n of the keys most recently denied by Incr, newest first, each with
+// its count and the time it was last denied. It's empty unless the cache was created WithBlockedHistory.
+func (c *Cache) RecentlyBlocked(n int) []BlockedKey {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.blocked == nil || n <= 0 {
+		return nil
+	}
+	blocked := make([]BlockedKey, 0, n)
+	for ent := c.blocked.order.Front(); ent != nil && len(blocked) < n; ent = ent.Next() {
+		blocked = append(blocked, ent.Value.(*blockedKey).bk)
+	}
+	return blocked
+}
@@ -0,0 +1,54 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecentlyBlocked(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(3, time.Hour, WithBlockedHistory(3))
+	rl.now = clock.Now
+	start := clock.Now()
+
+	// foo_0 to foo_4 each go over the limit of 1 in turn
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("foo_%d", i)
+		rl.Incr(key, 1)
+		rl.Incr(key, 1)
+	}
+	expected := []BlockedKey{{"foo_4", 2, start}, {"foo_3", 2, start}, {"foo_2", 2, start}}
+	if blocked := rl.RecentlyBlocked(10); !reflect.DeepEqual(blocked, expected) {
+		t.Fatalf("expected %v, got %v", expected, blocked)
+	}
+
+	// blocking a key again moves it to the front with its latest count and time
+	clock.Add(time.Minute)
+	rl.Incr("foo_3", 1)
+	expected = []BlockedKey{{"foo_3", 3, start.Add(time.Minute)}, {"foo_4", 2, start}}
+	if blocked := rl.RecentlyBlocked(2); !reflect.DeepEqual(blocked, expected) {
+		t.Fatalf("expected %v, got %v", expected, blocked)
+	}
+
+	// and the history outlives the entries themselves
+	for i := 0; i < 3; i++ {
+		rl.Incr(fmt.Sprintf("bar_%d", i), 1)
+	}
+	if rl.Contains("foo_3") {
+		t.Fatalf("expected foo_3 to be evicted from the cache")
+	}
+	if blocked := rl.RecentlyBlocked(1); len(blocked) != 1 || blocked[0].Key != "foo_3" {
+		t.Fatalf("expected foo_3 to stay in the history after eviction, got %v", blocked)
+	}
+}
+
+func TestRecentlyBlockedWithoutHistory(t *testing.T) {
+	rl, _ := New(3, time.Hour)
+	rl.Incr("foo", 1)
+	rl.Incr("foo", 1)
+	if blocked := rl.RecentlyBlocked(10); len(blocked) != 0 {
+		t.Fatalf("expected no history without WithBlockedHistory, got %v", blocked)
+	}
+}
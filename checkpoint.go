@@ -0,0 +1,119 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"errors"
+	"time"
+)
+
+// maxCheckpoints is how many checkpoints can be outstanding at once, taking another drops the oldest
+const maxCheckpoints = 8
+
+// ErrUnknownCheckpoint is returned by Rollback for a checkpoint that was released, rolled back past,
+// or dropped for being the oldest of too many
+var ErrUnknownCheckpoint = errors.New("Unknown checkpoint")
+
+// CheckpointID identifies a point the cache can be rolled back to, see Checkpoint
+type CheckpointID uint64
+
+type checkpoint struct {
+	id      CheckpointID
+	entries []checkpointEntry
+}
+
+type checkpointEntry struct {
+	id      interface{}
+	key     interface{}
+	value   uint64
+	total   uint64
+	updated time.Time
+	started time.Time
+	created time.Time
+}
+
+// Checkpoint saves the count, lifetime total and rate period of every entry, along with their order,
+// so a run of tentative increments can be undone with Rollback or kept with Release. Up to 8 checkpoints
+// can be outstanding, taking a 9th drops the oldest. A closed cache takes no checkpoint and returns 0.
+func (c *Cache) Checkpoint() CheckpointID {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0
+	}
+	c.nextCheckpoint++
+	cp := checkpoint{id: c.nextCheckpoint, entries: make([]checkpointEntry, 0, c.evictList.Len())}
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		cp.entries = append(cp.entries, checkpointEntry{e.id, e.key, e.value, e.total, e.updated, e.started, e.created})
+	}
+
+	c.checkpoints = append(c.checkpoints, cp)
+	if len(c.checkpoints) > maxCheckpoints {
+		c.checkpoints = c.checkpoints[1:]
+	}
+	return cp.id
+}
+
+// Rollback puts every entry back the way it was at the checkpoint. Keys added since are dropped without
+// calling OnEvicted, keys evicted or removed since come back, and keys still cached keep anything else
+// set on them, like a SetLimit limit. The checkpoint and any taken after it are used up.
+func (c *Cache) Rollback(id CheckpointID) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	for i, cp := range c.checkpoints {
+		if cp.id == id {
+			c.restore(cp)
+			c.checkpoints = c.checkpoints[:i]
+			return nil
+		}
+	}
+	return ErrUnknownCheckpoint
+}
+
+// Release keeps every change made since the checkpoint and forgets it, it's safe to call
+// for a checkpoint that's already gone.
+func (c *Cache) Release(id CheckpointID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	for i, cp := range c.checkpoints {
+		if cp.id == id {
+			c.checkpoints = append(c.checkpoints[:i], c.checkpoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// restore rebuilds the list and map from a checkpoint, reusing the entries that are still cached
+func (c *Cache) restore(cp checkpoint) {
+	evictList := list.New()
+	cache := make(map[interface{}]*list.Element, len(cp.entries))
+	for _, ce := range cp.entries {
+		var e *entry
+		if ent, ok := c.cache[ce.id]; ok {
+			e = ent.Value.(*entry)
+		} else {
+			e = &entry{id: ce.id, key: ce.key}
+		}
+		e.value, e.total, e.updated, e.started, e.created = ce.value, ce.total, ce.updated, ce.started, ce.created
+		cache[ce.id] = evictList.PushBack(e)
+	}
+
+	for id, ent := range c.cache {
+		if _, ok := cache[id]; !ok {
+			e := ent.Value.(*entry)
+			c.leaveGroups(e)
+			c.notifyAvailable(e)
+		}
+	}
+	c.evictList = evictList
+	c.cache = cache
+}
@@ -0,0 +1,99 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckpointRollback(t *testing.T) {
+	rl, _ := New(3, time.Hour)
+	rl.Incr("foo", 10)
+	rl.Incr("bar", 10)
+	rl.Incr("bar", 10)
+	rl.SetLimit("bar", 5)
+
+	var evicted int
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		evicted++
+	}
+
+	id := rl.Checkpoint()
+	rl.Incr("bar", 10)
+	rl.Incr("baz", 10)
+	rl.Incr("qux", 10) // evicts foo
+	if rl.Contains("foo") {
+		t.Fatalf("expected foo to be evicted by the tentative increments")
+	}
+
+	if err := rl.Rollback(id); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if evicted != 1 {
+		t.Fatalf("expected the rollback not to call OnEvicted, have [%d] calls", evicted)
+	}
+	for key, expected := range map[string]uint64{"foo": 1, "bar": 2} {
+		if value, _ := rl.Peek(key); value != expected {
+			t.Fatalf("expected %s back at [%d], got [%d]", key, expected, value)
+		}
+	}
+	if rl.Len() != 2 || rl.Contains("baz") || rl.Contains("qux") {
+		t.Fatalf("expected the keys added since the checkpoint to be dropped, have %v", rl.Keys())
+	}
+	if keys := rl.Keys(); keys[0] != "foo" || keys[1] != "bar" {
+		t.Fatalf("expected the checkpointed order, got %v", keys)
+	}
+	if limit, _ := rl.EffectiveLimit("bar"); limit != 5 {
+		t.Fatalf("expected bar to keep its limit, got [%d]", limit)
+	}
+
+	// a checkpoint is used up by rolling back to it
+	if err := rl.Rollback(id); err != ErrUnknownCheckpoint {
+		t.Fatalf("expected ErrUnknownCheckpoint rolling back twice, got %v", err)
+	}
+}
+
+func TestCheckpointRelease(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.Incr("foo", 10)
+
+	id := rl.Checkpoint()
+	rl.Incr("foo", 10)
+	rl.Release(id)
+	if value, _ := rl.Peek("foo"); value != 2 {
+		t.Fatalf("expected the increment to be kept, got [%d]", value)
+	}
+	if err := rl.Rollback(id); err != ErrUnknownCheckpoint {
+		t.Fatalf("expected a released checkpoint to be gone, got %v", err)
+	}
+}
+
+func TestCheckpointBound(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	first := rl.Checkpoint()
+	var ids []CheckpointID
+	for i := 0; i < maxCheckpoints; i++ {
+		rl.Incr("foo", 10)
+		ids = append(ids, rl.Checkpoint())
+	}
+	if len(rl.checkpoints) != maxCheckpoints {
+		t.Fatalf("expected at most [%d] checkpoints, have [%d]", maxCheckpoints, len(rl.checkpoints))
+	}
+	if err := rl.Rollback(first); err != ErrUnknownCheckpoint {
+		t.Fatalf("expected the oldest checkpoint to be dropped, got %v", err)
+	}
+
+	// rolling back to one in the middle uses up the ones after it
+	if err := rl.Rollback(ids[2]); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if value, _ := rl.Peek("foo"); value != 3 {
+		t.Fatalf("expected foo back at [3], got [%d]", value)
+	}
+	if err := rl.Rollback(ids[5]); err != ErrUnknownCheckpoint {
+		t.Fatalf("expected later checkpoints to be used up, got %v", err)
+	}
+	if err := rl.Rollback(ids[1]); err != nil {
+		t.Fatalf("expected earlier checkpoints to survive, got %v", err)
+	}
+}
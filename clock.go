@@ -0,0 +1,64 @@
+package ratelimiter
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// coarseClock caches the current time, refreshed by a ticker, so reading it is an atomic load
+type coarseClock struct {
+	nanos int64
+	stop  chan struct{}
+}
+
+func (cc *coarseClock) Now() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&cc.nanos)).UTC()
+}
+
+// WithCoarseClock has the cache read the time from a value a background goroutine refreshes
+// every resolution, rather than calling time.Now on every Incr that needs it. That's cheaper
+// under heavy load, the tradeoff is every window decision can be up to resolution late, so a
+// rate period can last up to resolution longer than asked for. Keep resolution well below the
+// ratePeriod, and call StopCoarseClock once the cache is no longer needed to stop the goroutine.
+func WithCoarseClock(resolution time.Duration) Option {
+	return func(c *Cache) {
+		if resolution <= 0 {
+			return
+		}
+
+		cc := &coarseClock{nanos: time.Now().UnixNano(), stop: make(chan struct{})}
+		c.clock = cc
+		c.now = cc.Now
+		go func() {
+			ticker := time.NewTicker(resolution)
+			defer ticker.Stop()
+			for {
+				select {
+				case t := <-ticker.C:
+					atomic.StoreInt64(&cc.nanos, t.UnixNano())
+				case <-cc.stop:
+					return
+				}
+			}
+		}()
+	}
+}
+
+// StopCoarseClock stops the goroutine started by WithCoarseClock and goes back to calling
+// time.Now, it's safe to call on a cache without a coarse clock.
+func (c *Cache) StopCoarseClock() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopCoarseClock()
+}
+
+// stopCoarseClock is StopCoarseClock for callers that already hold the write lock
+func (c *Cache) stopCoarseClock() {
+	if c.clock == nil {
+		return
+	}
+	close(c.clock.stop)
+	c.clock = nil
+	c.now = timeNow
+}
@@ -0,0 +1,62 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoarseClockWindows(t *testing.T) {
+	resolution := 10 * time.Millisecond
+	ratePeriod := 100 * time.Millisecond
+	rl, _ := New(10, ratePeriod, WithCoarseClock(resolution))
+	defer rl.StopCoarseClock()
+
+	key := "foo"
+	maxCount := 2
+	for i := 0; i < maxCount; i++ {
+		_, _ = rl.Incr(key, maxCount)
+	}
+	if _, underRateLimit := rl.Incr(key, maxCount); underRateLimit {
+		t.Fatalf("expected foo to be over the limit inside the rate period")
+	}
+
+	// well inside the period, even allowing for the clock lagging
+	time.Sleep(ratePeriod / 2)
+	if _, underRateLimit := rl.Incr(key, maxCount); underRateLimit {
+		t.Fatalf("expected foo to still be limited halfway through the rate period")
+	}
+
+	// past the period plus a couple of ticks of resolution it must have lifted
+	time.Sleep(ratePeriod/2 + 3*resolution)
+	if cnt, underRateLimit := rl.Incr(key, maxCount); !underRateLimit || cnt != 1 {
+		t.Fatalf("expected foo to reset within the clock resolution of the period, got count [%d]", cnt)
+	}
+}
+
+func TestStopCoarseClock(t *testing.T) {
+	rl, _ := New(10, time.Second, WithCoarseClock(time.Hour))
+
+	// with an hour resolution the cached time never moves
+	before := rl.now()
+	time.Sleep(5 * time.Millisecond)
+	if !rl.now().Equal(before) {
+		t.Fatalf("expected the coarse clock to hold its time between ticks")
+	}
+
+	rl.StopCoarseClock()
+	rl.StopCoarseClock()
+	if !rl.now().After(before) {
+		t.Fatalf("expected the cache to go back to the real clock once stopped")
+	}
+}
+
+// go test -bench=CoarseClock -run=XXX
+func BenchmarkIncrWithCoarseClock(b *testing.B) {
+	rl, _ := New(100, 2*time.Second, WithCoarseClock(10*time.Millisecond))
+	defer rl.StopCoarseClock()
+	maxCount := 10
+	key := "foo"
+	for n := 0; n < b.N; n++ {
+		rl.Incr(key, maxCount)
+	}
+}
@@ -0,0 +1,41 @@
+package ratelimiter
+
+import "errors"
+
+// ErrClosed is returned by methods that can fail when the cache has been closed
+var ErrClosed = errors.New("Cache is closed")
+
+// Close stops every background goroutine the cache started, the janitor, metrics and StatsD
+// exports, coarse clock, event stream and async eviction workers, delivering any queued OnEvicted
+// calls and events first. Once closed the cache is left as it is and reads as empty. Incr and the
+// methods built on it report every key as over the rate limit, Get, Peek, Contains, Lifetime, Inspect
+// and the other per key lookups report every key as missing, and Len, Keys, Stats, Metrics, OldestN,
+// SortedEntries, Range, MatchCounts and WindowAgeHistogram report an empty cache, though cumulative
+// counters are still reported. Remove, RemoveGroup, Swap, the Reset and Set methods, Protect,
+// Unprotect, ShrinkToFit, ReportHealth, RemoveBudget, Checkpoint and Release do nothing, the Start
+// methods and StreamEvents don't start anything, and Save, Load and Rollback return ErrClosed.
+// Closing again does nothing.
+func (c *Cache) Close() error {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.stopJanitor()
+	c.stopMetricsExport()
+	c.stopStatsDExport()
+	c.stopCoarseClock()
+	q, s := c.evictQueue, c.stream
+	c.evictQueue, c.stream = nil, nil
+	c.lock.Unlock()
+
+	// callbacks may call back into the cache, so deliver the rest without holding the lock
+	if q != nil {
+		q.stop()
+	}
+	if s != nil {
+		s.stop()
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	rl, _ := New(10, time.Minute, WithCoarseClock(time.Millisecond), WithAsyncEviction(1, 10))
+	rl.StartJanitor(time.Millisecond)
+	rl.StartMetricsExport(time.Millisecond, &lockedBuffer{})
+
+	_, _ = rl.Incr("foo", 10)
+	var snap bytes.Buffer
+	if err := rl.Save(&snap); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+
+	if err := rl.Close(); err != nil {
+		t.Fatalf("expected Close to succeed, got %v", err)
+	}
+	if err := rl.Close(); err != nil {
+		t.Fatalf("expected closing twice to do nothing, got %v", err)
+	}
+
+	if running, _, _ := rl.JanitorStatus(); running {
+		t.Fatalf("expected Close to stop the janitor")
+	}
+	if rl.metricsStop != nil || rl.clock != nil || rl.evictQueue != nil {
+		t.Fatalf("expected Close to stop every background goroutine")
+	}
+
+	if cnt, ok := rl.Incr("foo", 10); ok || cnt != 0 {
+		t.Fatalf("expected Incr on a closed cache to be rejected, got count [%d]", cnt)
+	}
+	if _, _, ok := rl.IncrFull("bar", 10); ok {
+		t.Fatalf("expected IncrFull on a closed cache to be rejected")
+	}
+	if _, ok := rl.IncrInGroup("bar", "group", 10); ok {
+		t.Fatalf("expected IncrInGroup on a closed cache to be rejected")
+	}
+	if _, ok := rl.Get("foo"); ok {
+		t.Fatalf("expected Get on a closed cache to report the key missing")
+	}
+	if _, ok := rl.Peek("foo"); ok {
+		t.Fatalf("expected Peek on a closed cache to report the key missing")
+	}
+	rl.Remove("foo")
+	if rl.evictList.Len() != 1 {
+		t.Fatalf("expected Remove on a closed cache to do nothing")
+	}
+	if rl.Contains("foo") || rl.Len() != 0 || len(rl.Keys()) != 0 {
+		t.Fatalf("expected a closed cache to report itself empty")
+	}
+	if _, ok := rl.Lifetime("foo"); ok {
+		t.Fatalf("expected Lifetime on a closed cache to report the key missing")
+	}
+
+	if err := rl.Save(&bytes.Buffer{}); err != ErrClosed {
+		t.Fatalf("expected Save on a closed cache to return ErrClosed, got %v", err)
+	}
+	if err := rl.Load(&snap); err != ErrClosed {
+		t.Fatalf("expected Load on a closed cache to return ErrClosed, got %v", err)
+	}
+}
+
+// nothing mutates a closed cache or starts a goroutine on it
+func TestClosedMutators(t *testing.T) {
+	rl, _ := New(10, time.Minute)
+	_, _ = rl.IncrInGroup("foo", "group", 10)
+	rl.Protect("foo")
+	cp := rl.Checkpoint()
+	var evicted int
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		evicted++
+	}
+	_ = rl.Close()
+
+	if removed := rl.RemoveGroup("group"); removed != 0 || evicted != 0 {
+		t.Fatalf("expected RemoveGroup on a closed cache to do nothing, removed [%d]", removed)
+	}
+	rl.Unprotect("foo")
+	rl.Release(cp)
+	rl.ShrinkToFit()
+	if id := rl.Checkpoint(); id != 0 || len(rl.checkpoints) != 1 {
+		t.Fatalf("expected Checkpoint on a closed cache to take nothing, got [%d]", id)
+	}
+
+	if _, existed := rl.Swap("foo", 5); existed {
+		t.Fatalf("expected Swap on a closed cache to do nothing")
+	}
+	rl.SetLimit("bar", 5)
+	rl.SetResetHandler("bar", func(previous uint64) {})
+	rl.Protect("bar")
+	rl.Reset("foo")
+	rl.ResetAll()
+	if _, ok := rl.ResetIfOlderThan("foo", 0); ok {
+		t.Fatalf("expected ResetIfOlderThan on a closed cache to report the key missing")
+	}
+	if n := rl.ResetWhere(func(key interface{}, value uint64) bool { return true }); n != 0 {
+		t.Fatalf("expected ResetWhere on a closed cache to reset nothing, got [%d]", n)
+	}
+	if _, ok := rl.IncrKind("bar", Read, 10); ok {
+		t.Fatalf("expected IncrKind on a closed cache to be rejected")
+	}
+	if _, ok := rl.IncrBanked("bar", 10, 10); ok {
+		t.Fatalf("expected IncrBanked on a closed cache to be rejected")
+	}
+	if rl.AllowEWMA("bar", 10) {
+		t.Fatalf("expected AllowEWMA on a closed cache to be rejected")
+	}
+	if _, ok := rl.Acquire("bar", 10); ok {
+		t.Fatalf("expected Acquire on a closed cache to be rejected")
+	}
+	if _, _, ok := rl.Reserve("bar", 1, 10); ok {
+		t.Fatalf("expected Reserve on a closed cache to be rejected")
+	}
+	if rl.IncrDistinctChild("bar", "child", 10) {
+		t.Fatalf("expected IncrDistinctChild on a closed cache to be rejected")
+	}
+
+	// the entry was left exactly as it was when the cache closed
+	if rl.evictList.Len() != 1 || rl.evictList.Front().Value.(*entry).value != 1 || len(rl.protected) != 1 {
+		t.Fatalf("expected a closed cache to be left alone")
+	}
+
+	rl.StartJanitor(time.Millisecond)
+	rl.StartMetricsExport(time.Millisecond, &lockedBuffer{})
+	rl.StartStatsDExport(time.Millisecond, nil, StatsDConfig{})
+	rl.StreamEvents(&lockedBuffer{})
+	if rl.janitor.stop != nil || rl.metricsStop != nil || rl.statsdStop != nil || rl.stream != nil {
+		t.Fatalf("expected nothing to start on a closed cache")
+	}
+}
+
+// every read agrees a closed cache is empty
+func TestClosedReads(t *testing.T) {
+	rl, _ := New(10, time.Minute)
+	_, _ = rl.Incr("foo", 10)
+	_ = rl.Close()
+
+	if rl.Stats().Len != 0 || rl.Metrics().Size != 0 || len(rl.Metrics().TopKeys) != 0 {
+		t.Fatalf("expected Stats and Metrics to report a closed cache empty")
+	}
+	if len(rl.OldestN(10)) != 0 || len(rl.SortedEntries()) != 0 || len(rl.MatchCounts("*")) != 0 {
+		t.Fatalf("expected OldestN, SortedEntries and MatchCounts to report a closed cache empty")
+	}
+	rl.Range(func(key interface{}, value uint64, updated time.Time) bool {
+		t.Fatalf("expected Range to see nothing in a closed cache, got [%v]", key)
+		return false
+	})
+	if h := rl.WindowAgeHistogram([]time.Duration{time.Hour}); h[time.Hour] != 0 {
+		t.Fatalf("expected WindowAgeHistogram to count nothing in a closed cache")
+	}
+	if _, ok := rl.Inspect("foo"); ok {
+		t.Fatalf("expected Inspect on a closed cache to report the key missing")
+	}
+	if _, ok := rl.MergedCount("foo"); ok {
+		t.Fatalf("expected MergedCount on a closed cache to report the key missing")
+	}
+}
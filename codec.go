@@ -0,0 +1,134 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Codec turns the keys and counts of a cache into bytes and back, for SaveWith and LoadWith
+type Codec interface {
+	Encode(entries []KeyCount) ([]byte, error)
+	Decode(data []byte) ([]KeyCount, error)
+}
+
+const (
+	// jsonFormat marks data written by JSONCodec
+	jsonFormat = "ratelimiter/json"
+
+	// gobMagic is written ahead of data encoded by GobCodec
+	gobMagic = "ratelimiter/gob\n"
+)
+
+// JSONCodec writes entries as JSON, which is easy to read when debugging. Keys come back as the types
+// encoding/json decodes into an interface{}, so numeric keys come back as float64.
+type JSONCodec struct{}
+
+type jsonSnapshot struct {
+	Format  string     `json:"format"`
+	Entries []KeyCount `json:"entries"`
+}
+
+// Encode encodes entries as JSON.
+func (JSONCodec) Encode(entries []KeyCount) ([]byte, error) {
+	return json.Marshal(jsonSnapshot{jsonFormat, entries})
+}
+
+// Decode decodes entries written by Encode.
+func (JSONCodec) Decode(data []byte) ([]KeyCount, error) {
+	var snap jsonSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil || snap.Format != jsonFormat {
+		return nil, fmt.Errorf("Data wasn't written by JSONCodec, it may have been saved with a different codec")
+	}
+	return snap.Entries, nil
+}
+
+// GobCodec writes entries with encoding/gob, which is more compact than JSON and keeps key types.
+// Any key type other than the basic types must be registered with gob.Register first.
+type GobCodec struct{}
+
+// Encode encodes entries with gob.
+func (GobCodec) Encode(entries []KeyCount) ([]byte, error) {
+	buf := bytes.NewBufferString(gobMagic)
+	if err := gob.NewEncoder(buf).Encode(entries); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes entries written by Encode.
+func (GobCodec) Decode(data []byte) ([]KeyCount, error) {
+	if !bytes.HasPrefix(data, []byte(gobMagic)) {
+		return nil, fmt.Errorf("Data wasn't written by GobCodec, it may have been saved with a different codec")
+	}
+	var entries []KeyCount
+	if err := gob.NewDecoder(bytes.NewReader(data[len(gobMagic):])).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("Unable to decode snapshot: %v", err)
+	}
+	return entries, nil
+}
+
+// SaveWith writes every key and its count to w using codec, most recently used first, so they can be
+// restored with LoadWith and the same codec. Unlike Save only keys and counts are written, the
+// cache's configuration and each key's window and lifetime total aren't.
+func (c *Cache) SaveWith(w io.Writer, codec Codec) error {
+	c.lock.RLock()
+	if c.closed {
+		c.lock.RUnlock()
+		return ErrClosed
+	}
+	entries := make([]KeyCount, 0, c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		entries = append(entries, KeyCount{e.key, e.value})
+	}
+	c.lock.RUnlock()
+
+	data, err := codec.Encode(entries)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadWith replaces the cache entries with ones written by SaveWith using the same codec, keeping
+// their recency order. Every key starts a fresh rate period with its saved count, and nil keys and
+// keys past MaxEntries are dropped. Nothing is changed if the data can't be decoded.
+func (c *Cache) LoadWith(r io.Reader, codec Codec) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("Unable to read snapshot: %v", err)
+	}
+	entries, err := codec.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	now := c.now()
+	c.dropEntries()
+	c.evictList = list.New()
+	c.cache = make(map[interface{}]*list.Element, len(entries))
+	for _, kc := range entries {
+		if c.evictList.Len() >= c.MaxEntries {
+			break
+		}
+		if kc.Key == nil {
+			continue
+		}
+		item := &entry{id: c.storedID(kc.Key), key: kc.Key, value: kc.Count, total: kc.Count, created: now}
+		c.startWindow(item, now)
+		c.cache[item.id] = c.evictList.PushBack(item)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": JSONCodec{}, "gob": GobCodec{}} {
+		rl, _ := New(10, time.Hour)
+		rl.Incr("foo", 10)
+		rl.Incr("bar", 10)
+		rl.Incr("bar", 10)
+		rl.Incr("baz", 10)
+
+		var buf bytes.Buffer
+		if err := rl.SaveWith(&buf, codec); err != nil {
+			t.Fatalf("unexpected error saving with %s: %v", name, err)
+		}
+
+		loaded, _ := New(2, time.Hour)
+		if err := loaded.LoadWith(&buf, codec); err != nil {
+			t.Fatalf("unexpected error loading with %s: %v", name, err)
+		}
+
+		// the most recent keys that fit are kept, in the same order
+		expected := []KeyCount{{"bar", 2}, {"baz", 1}}
+		if oldest := loaded.OldestN(2); !reflect.DeepEqual(oldest, expected) {
+			t.Fatalf("expected %v loading with %s, got %v", expected, name, oldest)
+		}
+	}
+}
+
+func TestCodecMismatch(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.Incr("foo", 10)
+
+	for _, pair := range [][2]Codec{{JSONCodec{}, GobCodec{}}, {GobCodec{}, JSONCodec{}}} {
+		var buf bytes.Buffer
+		_ = rl.SaveWith(&buf, pair[0])
+
+		loaded, _ := New(10, time.Hour)
+		loaded.Incr("bar", 10)
+		err := loaded.LoadWith(&buf, pair[1])
+		if err == nil || !strings.Contains(err.Error(), "different codec") {
+			t.Fatalf("expected a codec mismatch error, got %v", err)
+		}
+		if !loaded.Contains("bar") || loaded.Len() != 1 {
+			t.Fatalf("expected a failed load to leave the cache alone")
+		}
+	}
+}
+
+// null keys in a snapshot are skipped and the entries being replaced are let go
+func TestCodecLoadNilKeys(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.IncrInGroup("foo", "group", 1)
+	rl.IncrInGroup("foo", "group", 1)
+	available := rl.AvailableAfter("foo")
+
+	data := `{"format":"` + jsonFormat + `","entries":[{"Key":null,"Count":3},{"Key":"foo","Count":2}]}`
+	if err := rl.LoadWith(strings.NewReader(data), JSONCodec{}); err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if rl.Len() != 1 || !rl.Contains("foo") {
+		t.Fatalf("expected the null key to be skipped, have %v", rl.Keys())
+	}
+
+	select {
+	case <-available:
+	default:
+		t.Fatalf("expected AvailableAfter waiters on the replaced entry to be woken")
+	}
+	if removed := rl.RemoveGroup("group"); removed != 0 {
+		t.Fatalf("expected the loaded key to have left the old entry's group, removed [%d]", removed)
+	}
+}
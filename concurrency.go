@@ -0,0 +1,37 @@
+package ratelimiter
+
+// Acquire takes one of maxConcurrent in-flight slots for key, this caps how many operations
+// can run at once for a key rather than how many happen over time. When ok is true the caller
+// must call release once the operation finishes, calling it more than once is harmless.
+// When every slot is taken ok is false and release is nil.
+// Tracked keys are bounded by the LRU like any other entry, if a key is evicted while
+// operations are in flight it starts over with no slots taken.
+func (c *Cache) Acquire(key interface{}, maxConcurrent int) (release func(), ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return nil, false
+	}
+
+	e, ok := c.admit(key)
+	if !ok {
+		return nil, false
+	}
+	if e.inflight >= maxConcurrent {
+		return nil, false
+	}
+	e.inflight++
+
+	released := false
+	return func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if released {
+			return
+		}
+		released = true
+		e.inflight--
+	}, true
+}
@@ -0,0 +1,46 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	key := "foo"
+	maxConcurrent := 3
+
+	var releases []func()
+	for i := 0; i < maxConcurrent; i++ {
+		release, ok := rl.Acquire(key, maxConcurrent)
+		if !ok {
+			t.Fatalf("expected acquire [%d] of [%d] to succeed", i+1, maxConcurrent)
+		}
+		releases = append(releases, release)
+	}
+
+	if _, ok := rl.Acquire(key, maxConcurrent); ok {
+		t.Fatalf("expected acquiring beyond [%d] in-flight operations to fail", maxConcurrent)
+	}
+
+	// other keys have their own slots
+	if _, ok := rl.Acquire("bar", maxConcurrent); !ok {
+		t.Fatalf("expected a different key to be unaffected")
+	}
+
+	releases[0]()
+	if _, ok := rl.Acquire(key, maxConcurrent); !ok {
+		t.Fatalf("expected acquire to succeed again after a release")
+	}
+
+	// releasing twice shouldn't hand out an extra slot
+	releases[1]()
+	releases[1]()
+	if _, ok := rl.Acquire(key, maxConcurrent); !ok {
+		t.Fatalf("expected acquire to succeed after the second release")
+	}
+	if _, ok := rl.Acquire(key, maxConcurrent); ok {
+		t.Fatalf("expected a double release to only free a single slot")
+	}
+}
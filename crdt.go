@@ -0,0 +1,54 @@
+package ratelimiter
+
+// MergeCRDT merges another node's counts into the cache for limiting across several nodes. Each key
+// keeps the highest count it's been sent for every node apart from its own local count, the same as a
+// G-counter, so within a rate period merging is commutative and idempotent: merging the same counts
+// again, or an older set after a newer one, changes nothing, and the order merges arrive in doesn't
+// matter. Incr then limits a key on its local count plus the latest from every other node. Nodes
+// should send their counts for the current rate period, remote counts are dropped when the key's rate
+// period rolls over or it's reset, and keys that aren't cached are added. Counts don't say which period
+// they were taken in, so a snapshot from the last period that arrives after the key rolls over counts
+// against the new period until the period ends. Keep the nodes' clocks close and send snapshots often.
+func (c *Cache) MergeCRDT(nodeID string, entries []KeyCount) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	for _, kc := range entries {
+		if kc.Key == nil {
+			continue
+		}
+		e := c.lookup(kc.Key)
+		if current := e.remote[nodeID]; kc.Count > current {
+			if e.remote == nil {
+				e.remote = make(map[string]uint64)
+			}
+			e.remote[nodeID] = kc.Count
+			e.remoteTotal += kc.Count - current
+		}
+	}
+}
+
+// MergedCount returns key's local count plus the latest count merged in from every other node by
+// MergeCRDT, which is what Incr limits it on.
+func (c *Cache) MergedCount(key interface{}) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0, false
+	}
+	if ent, ok := c.find(key); ok {
+		e := ent.Value.(*entry)
+		return e.value + e.remoteTotal, true
+	}
+	return 0, false
+}
+
+// clearRemote drops the counts merged in from other nodes when the entry starts over
+func (c *Cache) clearRemote(e *entry) {
+	e.remote = nil
+	e.remoteTotal = 0
+}
@@ -0,0 +1,94 @@
+package ratelimiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMergeCRDTIdempotent(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.Incr("foo", 10)
+	rl.Incr("foo", 10)
+
+	remote := []KeyCount{{"foo", 3}, {"bar", 1}}
+	rl.MergeCRDT("node-b", remote)
+	rl.MergeCRDT("node-b", remote)
+	if count, _ := rl.MergedCount("foo"); count != 5 {
+		t.Fatalf("expected merging the same snapshot twice to count once for [5], got [%d]", count)
+	}
+
+	// an older snapshot arriving late doesn't take the count back down
+	rl.MergeCRDT("node-b", []KeyCount{{"foo", 1}})
+	if count, _ := rl.MergedCount("foo"); count != 5 {
+		t.Fatalf("expected a stale snapshot to change nothing, got [%d]", count)
+	}
+	if count, _ := rl.MergedCount("bar"); count != 1 {
+		t.Fatalf("expected a key only seen remotely to be added, got [%d]", count)
+	}
+	if value, _ := rl.Peek("foo"); value != 2 {
+		t.Fatalf("expected the local count to be kept apart, got [%d]", value)
+	}
+}
+
+func TestMergeCRDTTwoNodes(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+	rl.Incr("foo", 10)
+
+	// snapshots from two nodes arriving concurrently and repeatedly
+	var wg sync.WaitGroup
+	for _, node := range []string{"node-b", "node-c"} {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(node string) {
+				defer wg.Done()
+				rl.MergeCRDT(node, []KeyCount{{"foo", 4}})
+			}(node)
+		}
+	}
+	wg.Wait()
+	if count, _ := rl.MergedCount("foo"); count != 9 {
+		t.Fatalf("expected [1] local plus [4] from each node, got [%d]", count)
+	}
+
+	// Incr limits on the merged count
+	if _, ok := rl.Incr("foo", 10); !ok {
+		t.Fatalf("expected [10] merged to be at the limit")
+	}
+	if _, ok := rl.Incr("foo", 10); ok {
+		t.Fatalf("expected [11] merged to be over the limit")
+	}
+
+	// and the remote counts go with the rate period
+	clock.Add(11 * time.Second)
+	rl.Incr("foo", 10)
+	if count, _ := rl.MergedCount("foo"); count != 1 {
+		t.Fatalf("expected the new period to start from just the local count, got [%d]", count)
+	}
+}
+
+// a snapshot from before the rollover that arrives after it counts against the new period
+func TestMergeCRDTAfterRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+	rl.Incr("foo", 10)
+	rl.MergeCRDT("node-b", []KeyCount{{"foo", 4}})
+
+	// the period only rolls over once the key goes over its limit
+	clock.Add(11 * time.Second)
+	rl.Incr("foo", 1)
+	rl.MergeCRDT("node-b", []KeyCount{{"foo", 6}})
+	if count, _ := rl.MergedCount("foo"); count != 7 {
+		t.Fatalf("expected the late snapshot to count in the new period for [7], got [%d]", count)
+	}
+
+	// it's dropped once that period is over too
+	clock.Add(11 * time.Second)
+	rl.Incr("foo", 1)
+	if count, _ := rl.MergedCount("foo"); count != 1 {
+		t.Fatalf("expected the next period to start from just the local count, got [%d]", count)
+	}
+}
@@ -0,0 +1,34 @@
+package ratelimiter
+
+// CumulativeStats are cache wide totals that only ever go up for the life of the cache, whatever
+// happens to the counts of individual keys, so they can be exported as Prometheus counters as is.
+type CumulativeStats struct {
+
+	// Increments is every increment through Incr, IncrFull, IncrDetailed, IncrInGroup,
+	// IncrShared, AllowAtTime and Replay. The methods with counters of their own, like
+	// IncrKind, IncrBanked, AllowEWMA, Reserve, AddUnique and IncrDistinctChild, aren't counted.
+	Increments uint64
+
+	// Blocks is every increment counted in Increments that was denied for being over the rate limit
+	Blocks uint64
+
+	// Resets is every time a key's count started over, by its rate period rolling
+	// over, whichever method noticed it, or by Reset and the like
+	Resets uint64
+
+	// Evictions is every entry removed for capacity or by the janitor
+	Evictions uint64
+}
+
+// CumulativeStats returns the cache's monotonic totals, see CumulativeStats.
+func (c *Cache) CumulativeStats() CumulativeStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return CumulativeStats{
+		Increments: c.counters.increments,
+		Blocks:     c.counters.violations,
+		Resets:     c.counters.resets,
+		Evictions:  c.counters.evictions,
+	}
+}
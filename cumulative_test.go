@@ -0,0 +1,52 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCumulativeStats(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(2, 10*time.Second)
+	rl.now = clock.Now
+
+	var last CumulativeStats
+	check := func(expected CumulativeStats) {
+		stats := rl.CumulativeStats()
+		if stats.Increments < last.Increments || stats.Blocks < last.Blocks || stats.Resets < last.Resets || stats.Evictions < last.Evictions {
+			t.Fatalf("expected the counters never to go down, went from %+v to %+v", last, stats)
+		}
+		if stats != expected {
+			t.Fatalf("expected %+v, got %+v", expected, stats)
+		}
+		last = stats
+	}
+
+	for i := 0; i < 3; i++ {
+		rl.Incr("foo", 2)
+	}
+	check(CumulativeStats{Increments: 3, Blocks: 1})
+
+	// the window rolling over resets the key's count but not the totals
+	clock.Add(11 * time.Second)
+	if cnt, _ := rl.Incr("foo", 2); cnt != 1 {
+		t.Fatalf("expected foo to start over, got [%d]", cnt)
+	}
+	check(CumulativeStats{Increments: 4, Blocks: 1, Resets: 1})
+
+	rl.ResetAll()
+	check(CumulativeStats{Increments: 4, Blocks: 1, Resets: 2})
+
+	// nor does purging the cache
+	for i := 0; i < 4; i++ {
+		rl.Incr(fmt.Sprintf("bar_%d", i), 2)
+	}
+	check(CumulativeStats{Increments: 8, Blocks: 1, Resets: 2, Evictions: 3})
+	clock.Add(11 * time.Second)
+	rl.sweep()
+	if rl.Len() != 0 {
+		t.Fatalf("expected the janitor to purge the cache, have [%d]", rl.Len())
+	}
+	check(CumulativeStats{Increments: 8, Blocks: 1, Resets: 2, Evictions: 5})
+}
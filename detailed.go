@@ -0,0 +1,76 @@
+package ratelimiter
+
+// Reason says why IncrDetailed allowed or denied an increment
+type Reason int
+
+const (
+	// ReasonAllowed means the key is under its rate limit
+	ReasonAllowed Reason = iota
+
+	// ReasonRateLimited means the key is over its rate limit
+	ReasonRateLimited
+
+	// ReasonCapacityFull means the key is new and the cache was created WithRejectWhenFull
+	// and is full, so there was no room to start counting it
+	ReasonCapacityFull
+)
+
+// String returns the reason's name.
+func (r Reason) String() string {
+	switch r {
+	case ReasonAllowed:
+		return "allowed"
+	case ReasonRateLimited:
+		return "rate limited"
+	case ReasonCapacityFull:
+		return "capacity full"
+	}
+	return "unknown"
+}
+
+// WithRejectWhenFull makes a full cache decline new keys in Incr and every other method that counts
+// against a key, like IncrKind, Reserve and Acquire, rather than evicting the oldest entry to make room,
+// so keys already being counted can't be pushed out by a flood of new ones. A declined key is reported
+// as over the rate limit, or as ReasonCapacityFull by IncrDetailed, and WouldAllow and WouldEvict take
+// it into account. Methods that set a key without counting against it, like SetLimit, Swap and
+// MergeCRDT, still evict.
+func WithRejectWhenFull() Option {
+	return func(c *Cache) {
+		c.rejectWhenFull = true
+	}
+}
+
+// IncrDetailed increments key the same as Incr and says why the increment was allowed or denied,
+// so callers can tell a key over its rate limit from a full cache turning it away.
+func (c *Cache) IncrDetailed(key interface{}, maxValue int) (uint64, Reason) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key != nil && !c.closed && c.full(key) {
+		return 0, ReasonCapacityFull
+	}
+	value, ok := c.incr(key, maxValue)
+	if !ok {
+		return value, ReasonRateLimited
+	}
+	return value, ReasonAllowed
+}
+
+// admit returns the entry for key the same as lookup, or false if it's a new key that a full
+// WithRejectWhenFull cache declines, for the methods that count against a key
+func (c *Cache) admit(key interface{}) (*entry, bool) {
+	if c.full(key) {
+		return nil, false
+	}
+	return c.lookup(key), true
+}
+
+// full reports whether key would be declined for capacity, as a new key in a full
+// WithRejectWhenFull cache
+func (c *Cache) full(key interface{}) bool {
+	if !c.rejectWhenFull || c.evictList.Len() < c.MaxEntries {
+		return false
+	}
+	_, ok := c.find(key)
+	return !ok
+}
@@ -0,0 +1,93 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncrDetailed(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	if _, reason := rl.IncrDetailed("key", 1); reason != ReasonAllowed {
+		t.Fatalf("expected [%s], got [%s]", ReasonAllowed, reason)
+	}
+	if value, reason := rl.IncrDetailed("key", 1); reason != ReasonRateLimited || value != 2 {
+		t.Fatalf("expected [%s] at [2], got [%s] at [%d]", ReasonRateLimited, reason, value)
+	}
+}
+
+func TestIncrDetailedCapacityFull(t *testing.T) {
+	rl, _ := New(2, time.Hour, WithRejectWhenFull())
+
+	rl.Incr("foo", 1)
+	rl.Incr("bar", 1)
+
+	if _, reason := rl.IncrDetailed("baz", 1); reason != ReasonCapacityFull {
+		t.Fatalf("expected a new key in a full cache to get [%s], got [%s]", ReasonCapacityFull, reason)
+	}
+	if _, ok := rl.Incr("baz", 1); ok || rl.Contains("baz") {
+		t.Fatalf("expected Incr to decline the new key too")
+	}
+	if _, reason := rl.IncrDetailed("foo", 1); reason != ReasonRateLimited {
+		t.Fatalf("expected an existing key over its limit to get [%s], got [%s]", ReasonRateLimited, reason)
+	}
+	if !rl.Contains("foo") || !rl.Contains("bar") || rl.Stats().Evictions != 0 {
+		t.Fatalf("expected nothing to be evicted")
+	}
+
+	// room again once a key is removed
+	rl.Remove("bar")
+	if _, reason := rl.IncrDetailed("baz", 1); reason != ReasonAllowed {
+		t.Fatalf("expected the new key to be let in with room, got [%s]", reason)
+	}
+}
+
+// the methods that look the key up again after incr mustn't trip over a declined key
+func TestRejectWhenFullIncrFullAndGroup(t *testing.T) {
+	rl, _ := New(1, time.Second, WithRejectWhenFull())
+	rl.Incr("a", 5)
+
+	if windowed, lifetime, ok := rl.IncrFull("b", 5); ok || windowed != 0 || lifetime != 0 {
+		t.Fatalf("expected IncrFull to decline the new key, got [%d] [%d] [%t]", windowed, lifetime, ok)
+	}
+	if _, ok := rl.IncrInGroup("b", "g", 5); ok {
+		t.Fatalf("expected IncrInGroup to decline the new key")
+	}
+	if removed := rl.RemoveGroup("g"); removed != 0 || !rl.Contains("a") {
+		t.Fatalf("expected the declined key not to join the group, removed [%d]", removed)
+	}
+}
+
+func TestRejectWhenFullCountingMethods(t *testing.T) {
+	rl, _ := New(1, time.Hour, WithRejectWhenFull(), WithUniqueLimit(10))
+	rl.Incr("a", 5)
+
+	if rl.WouldAllow("b", 5) {
+		t.Fatalf("expected WouldAllow to predict the new key being declined")
+	}
+	if n := rl.WouldEvict(3); n != 0 {
+		t.Fatalf("expected WouldEvict to predict no evictions, got [%d]", n)
+	}
+
+	declined := map[string]bool{}
+	_, ok := rl.IncrKind("b", Read, 5)
+	declined["IncrKind"] = !ok
+	_, ok = rl.IncrBanked("b", 5, 5)
+	declined["IncrBanked"] = !ok
+	declined["AllowEWMA"] = !rl.AllowEWMA("b", 5)
+	_, _, ok = rl.Reserve("b", 1, 5)
+	declined["Reserve"] = !ok
+	_, ok = rl.Acquire("b", 5)
+	declined["Acquire"] = !ok
+	declined["IncrDistinctChild"] = !rl.IncrDistinctChild("b", "child", 5)
+	_, ok = rl.AddUnique("b", "item")
+	declined["AddUnique"] = !ok
+	for name, wasDeclined := range declined {
+		if !wasDeclined {
+			t.Fatalf("expected %s to decline the new key", name)
+		}
+	}
+	if !rl.Contains("a") || rl.Contains("b") {
+		t.Fatalf("expected nothing to be evicted for the new key, have %v", rl.Keys())
+	}
+}
@@ -0,0 +1,37 @@
+package ratelimiter
+
+// IncrDistinctChild records that parent touched child and reports whether parent is still at or under
+// maxDistinct distinct children for the current rate period, e.g. to catch an IP trying too many
+// usernames. Touching the same child again doesn't count twice. Only maxDistinct+1 children are kept
+// per parent, once a parent is over the limit further children aren't stored. The set is cleared when
+// the parent's rate period is over, which is shared with Incr on the same key.
+func (c *Cache) IncrDistinctChild(parent, child interface{}, maxDistinct int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if parent == nil || c.closed {
+		return false
+	}
+
+	e, ok := c.admit(parent)
+	if !ok {
+		return false
+	}
+	if now := c.now(); c.expired(e, now) {
+		c.rollover(e, parent, e.value, now)
+		e.value = 0
+	}
+
+	if _, ok := e.children[child]; ok {
+		return len(e.children) <= maxDistinct
+	}
+	if len(e.children) > maxDistinct {
+		return false
+	}
+
+	if e.children == nil {
+		e.children = make(map[interface{}]struct{})
+	}
+	e.children[child] = struct{}{}
+	return len(e.children) <= maxDistinct
+}
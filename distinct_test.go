@@ -0,0 +1,89 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIncrDistinctChild(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	ip := "10.0.0.1"
+	maxDistinct := 3
+
+	for i := 0; i < maxDistinct; i++ {
+		user := fmt.Sprintf("user_%d", i)
+		// hitting the same username repeatedly doesn't count as scanning
+		for j := 0; j < 5; j++ {
+			if !rl.IncrDistinctChild(ip, user, maxDistinct) {
+				t.Fatalf("expected [%d] distinct usernames to be allowed", i+1)
+			}
+		}
+	}
+
+	if rl.IncrDistinctChild(ip, "user_3", maxDistinct) {
+		t.Fatalf("expected a [%d]th distinct username to be blocked", maxDistinct+1)
+	}
+	if rl.IncrDistinctChild(ip, "user_0", maxDistinct) {
+		t.Fatalf("expected the parent to stay blocked for known children once over the limit")
+	}
+
+	// only one child over the limit is stored
+	for i := 4; i < 100; i++ {
+		_ = rl.IncrDistinctChild(ip, fmt.Sprintf("user_%d", i), maxDistinct)
+	}
+	if n := len(rl.cache[ip].Value.(*entry).children); n != maxDistinct+1 {
+		t.Fatalf("expected the child set to be bounded at [%d] got [%d]", maxDistinct+1, n)
+	}
+
+	if !rl.IncrDistinctChild("10.0.0.2", "user_0", maxDistinct) {
+		t.Fatalf("expected another parent to be unaffected")
+	}
+
+	clock.Add(11 * time.Second)
+	if !rl.IncrDistinctChild(ip, "user_50", maxDistinct) {
+		t.Fatalf("expected the parent to be allowed again in a new rate period")
+	}
+}
+
+func TestIncrDistinctChildReset(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithUniqueLimit(1))
+	rl.IncrDistinctChild("ip", "user_0", 1)
+	if rl.IncrDistinctChild("ip", "user_1", 1) {
+		t.Fatalf("expected a second distinct child to be blocked")
+	}
+	rl.AddUnique("ip", "item_0")
+	if _, ok := rl.AddUnique("ip", "item_1"); ok {
+		t.Fatalf("expected a second unique item to be over the limit")
+	}
+
+	rl.Reset("ip")
+	if !rl.IncrDistinctChild("ip", "user_1", 1) {
+		t.Fatalf("expected Reset to clear the child set")
+	}
+	if n, ok := rl.AddUnique("ip", "item_1"); !ok || n != 1 {
+		t.Fatalf("expected Reset to clear the unique items, got an estimate of [%d]", n)
+	}
+}
+
+// IncrDistinctChild starting the shared period over doesn't carry a blocked Incr count into it
+func TestIncrDistinctChildRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Minute)
+	rl.now = clock.Now
+
+	for i := 0; i < 5; i++ {
+		rl.Incr("ip", 2)
+	}
+	clock.Add(2 * time.Minute)
+	rl.IncrDistinctChild("ip", "user_0", 3)
+	if _, ok := rl.Incr("ip", 2); !ok {
+		t.Fatalf("expected Incr to be allowed in the period IncrDistinctChild started")
+	}
+	if rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be counted")
+	}
+}
@@ -0,0 +1,30 @@
+package ratelimiter
+
+// WithEverSeen remembers every key added to the cache in a bloom filter sized for about n distinct keys,
+// for EverSeen. Its memory is fixed at 2 bytes per key of n, and once more than n distinct keys have
+// been added EverSeen starts wrongly reporting more and more unseen keys as seen.
+func WithEverSeen(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.everSeen = newBloom(n)
+		}
+	}
+}
+
+// EverSeen reports whether key has ever been added to the cache, even if it's been evicted or removed
+// since, e.g. to spot the first time a key shows up. It can report a key that was never added as seen,
+// rarely while the cache has seen fewer distinct keys than WithEverSeen was sized for, but never the
+// other way round. Without WithEverSeen it's the same as Contains.
+func (c *Cache) EverSeen(key interface{}) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return false
+	}
+	if c.everSeen == nil {
+		_, ok := c.find(key)
+		return ok
+	}
+	return c.everSeen.has(key)
+}
@@ -0,0 +1,47 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEverSeen(t *testing.T) {
+	n := 1000
+	rl, _ := New(10, time.Hour, WithEverSeen(n))
+
+	for i := 0; i < n; i++ {
+		rl.Incr(fmt.Sprintf("seen_%d", i), 10)
+	}
+	rl.Remove("seen_999")
+	if rl.Contains("seen_0") || rl.Contains("seen_999") {
+		t.Fatalf("expected the first keys to be evicted and the last removed")
+	}
+
+	// no false negatives, evicted or removed
+	for i := 0; i < n; i++ {
+		if key := fmt.Sprintf("seen_%d", i); !rl.EverSeen(key) {
+			t.Fatalf("expected %s to have been seen", key)
+		}
+	}
+
+	// and few false positives while within the size
+	var falsePositives int
+	for i := 0; i < 10000; i++ {
+		if rl.EverSeen(fmt.Sprintf("unseen_%d", i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 100 {
+		t.Fatalf("expected under 1%% false positives, got [%d] in [10000]", falsePositives)
+	}
+}
+
+func TestEverSeenWithoutFilter(t *testing.T) {
+	rl, _ := New(1, time.Hour)
+	rl.Incr("foo", 10)
+	rl.Incr("bar", 10)
+	if rl.EverSeen("foo") || !rl.EverSeen("bar") {
+		t.Fatalf("expected EverSeen to only know the current entries without WithEverSeen")
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultEWMAWeight is the weight AllowEWMA gives the most recent rate period
+// when Cache.EWMAWeight isn't set
+const DefaultEWMAWeight = 0.3
+
+// AllowEWMA counts an event for key and reports whether the key's average rate is still
+// at or under maxRate. The average is an exponentially weighted moving average of the
+// per ratePeriod counts, so a single busy period is tolerated as long as the periods before
+// it were quiet, while a sustained high rate eventually blocks. With a ratePeriod of 0 the
+// whole lifetime of the key is a single period.
+func (c *Cache) AllowEWMA(key interface{}, maxRate float64) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return false
+	}
+
+	weight := c.ewmaWeight()
+	now := c.now()
+	e, ok := c.admit(key)
+	if !ok {
+		return false
+	}
+	if e.ewmaWindow.IsZero() {
+		e.ewmaWindow = now
+	}
+
+	// fold any completed periods into the average, periods with no events decay it
+	if c.ratePeriod > 0 {
+		if periods := int64(now.Sub(e.ewmaWindow) / c.ratePeriod); periods > 0 {
+			e.ewma = weight*float64(e.ewmaCount) + (1-weight)*e.ewma
+			e.ewma *= math.Pow(1-weight, float64(periods-1))
+			e.ewmaCount = 0
+			e.ewmaWindow = e.ewmaWindow.Add(c.ratePeriod * time.Duration(periods))
+		}
+	}
+
+	e.ewmaCount++
+	return weight*float64(e.ewmaCount)+(1-weight)*e.ewma <= maxRate
+}
+
+// ewmaWeight returns EWMAWeight if it's valid, DefaultEWMAWeight otherwise
+func (c *Cache) ewmaWeight() float64 {
+	if c.EWMAWeight <= 0 || c.EWMAWeight > 1 {
+		return DefaultEWMAWeight
+	}
+	return c.EWMAWeight
+}
@@ -0,0 +1,79 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// a single busy period after a run of quiet ones should be smoothed over
+func TestAllowEWMAToleratesSpike(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(100, time.Second)
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	maxRate := 10.0
+
+	// quiet periods of 5 events each
+	for p := 0; p < 5; p++ {
+		for i := 0; i < 5; i++ {
+			if !rl.AllowEWMA(key, maxRate) {
+				t.Fatalf("expected quiet traffic to be allowed in period [%d]", p)
+			}
+		}
+		now = now.Add(time.Second)
+	}
+
+	// a spike of 20 is double the rate, but the average should absorb it
+	for i := 0; i < 20; i++ {
+		if !rl.AllowEWMA(key, maxRate) {
+			t.Fatalf("expected a single spike to be tolerated, blocked at event [%d]", i)
+		}
+	}
+}
+
+func TestAllowEWMABlocksSustainedRate(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(100, time.Second)
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	maxRate := 10.0
+
+	blocked := false
+	for p := 0; p < 10 && !blocked; p++ {
+		for i := 0; i < 20; i++ {
+			if !rl.AllowEWMA(key, maxRate) {
+				blocked = true
+				if p == 0 {
+					t.Fatalf("expected the first busy period to be tolerated")
+				}
+				break
+			}
+		}
+		now = now.Add(time.Second)
+	}
+	if !blocked {
+		t.Fatalf("expected a sustained rate of 20 per period to eventually block at max rate [%f]", maxRate)
+	}
+}
+
+// periods with no traffic should decay the average so a key recovers
+func TestAllowEWMADecays(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(100, time.Second)
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	for p := 0; p < 10; p++ {
+		for i := 0; i < 20; i++ {
+			_ = rl.AllowEWMA(key, 10)
+		}
+		now = now.Add(time.Second)
+	}
+
+	now = now.Add(20 * time.Second)
+	if !rl.AllowEWMA(key, 10) {
+		t.Fatalf("expected the average to have decayed after a long quiet stretch")
+	}
+}
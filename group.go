@@ -0,0 +1,68 @@
+package ratelimiter
+
+// IncrInGroup increments key the same as Incr and records it as a member of group,
+// so it can be removed along with the rest of the group by RemoveGroup.
+// A key can belong to any number of groups.
+func (c *Cache) IncrInGroup(key, group interface{}, maxValue int) (uint64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return 0, false
+	}
+	cnt, underRateLimit := c.incr(key, maxValue)
+	ent, ok := c.find(key)
+	if !ok {
+		// declined by WithRejectWhenFull, so there's nothing to add to the group
+		return cnt, underRateLimit
+	}
+
+	if c.groups == nil {
+		c.groups = make(map[interface{}]map[interface{}]struct{})
+	}
+	members, ok := c.groups[group]
+	if !ok {
+		members = make(map[interface{}]struct{})
+		c.groups[group] = members
+	}
+	e := ent.Value.(*entry)
+	if _, ok := members[e.id]; !ok {
+		members[e.id] = struct{}{}
+		e.groups = append(e.groups, group)
+	}
+
+	return cnt, underRateLimit
+}
+
+// RemoveGroup removes every key in group from the cache and returns how many were removed.
+// Keys removed this way also leave any other groups they were in.
+func (c *Cache) RemoveGroup(group interface{}) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0
+	}
+	members := c.groups[group]
+	removed := 0
+	for id := range members {
+		if ent, ok := c.cache[id]; ok {
+			c.removeElement(ent)
+			removed++
+		}
+	}
+	delete(c.groups, group)
+	return removed
+}
+
+// leaveGroups drops an entry that's leaving the cache from all of its groups
+func (c *Cache) leaveGroups(e *entry) {
+	for _, group := range e.groups {
+		members := c.groups[group]
+		delete(members, e.id)
+		if len(members) == 0 {
+			delete(c.groups, group)
+		}
+	}
+	e.groups = nil
+}
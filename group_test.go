@@ -0,0 +1,64 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemoveGroup(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	_, _ = rl.IncrInGroup("foo", "session1", 10)
+	_, _ = rl.IncrInGroup("bar", "session1", 10)
+	_, _ = rl.IncrInGroup("bar", "session2", 10)
+	_, _ = rl.IncrInGroup("baz", "session2", 10)
+	_, _ = rl.Incr("qux", 10)
+
+	if cnt, _ := rl.Get("bar"); cnt != 2 {
+		t.Fatalf("expected bar to have a count of [2] but got [%d]", cnt)
+	}
+
+	removed := rl.RemoveGroup("session1")
+	if removed != 2 {
+		t.Fatalf("expected [2] keys removed from session1 but got [%d]", removed)
+	}
+	for _, key := range []string{"foo", "bar"} {
+		if _, ok := rl.Get(key); ok {
+			t.Fatalf("expected %s to be removed with session1", key)
+		}
+	}
+	for _, key := range []string{"baz", "qux"} {
+		if _, ok := rl.Get(key); !ok {
+			t.Fatalf("expected %s to survive removing session1", key)
+		}
+	}
+
+	// bar already went with session1 so only baz is left in session2
+	removed = rl.RemoveGroup("session2")
+	if removed != 1 {
+		t.Fatalf("expected [1] key removed from session2 but got [%d]", removed)
+	}
+	if rl.Len() != 1 {
+		t.Fatalf("expected only qux to be left but have [%d] keys", rl.Len())
+	}
+
+	if removed := rl.RemoveGroup("missing"); removed != 0 {
+		t.Fatalf("expected removing an unknown group to remove nothing, removed [%d]", removed)
+	}
+}
+
+// an evicted key shouldn't linger in its group
+func TestRemoveGroupAfterEviction(t *testing.T) {
+	rl, _ := New(2, 10*time.Second)
+
+	_, _ = rl.IncrInGroup("foo", "session1", 10)
+	_, _ = rl.IncrInGroup("bar", "session1", 10)
+	_, _ = rl.Incr("baz", 10)
+
+	if removed := rl.RemoveGroup("session1"); removed != 1 {
+		t.Fatalf("expected [1] key removed after foo was evicted but got [%d]", removed)
+	}
+	if _, ok := rl.Get("baz"); !ok {
+		t.Fatalf("expected baz to survive removing session1")
+	}
+}
@@ -0,0 +1,40 @@
+package ratelimiter
+
+import (
+	"sort"
+	"time"
+)
+
+// WindowAgeHistogram tallies entries by how long ago their current rate period began. Each bucket is
+// an upper bound, an entry is counted under the smallest bucket its window age is at most, and anything
+// older than the largest bucket is counted under the largest too, so it reads as that age and over.
+// Every bucket is in the result, even if nothing falls in it.
+func (c *Cache) WindowAgeHistogram(buckets []time.Duration) map[time.Duration]int {
+	bounds := append([]time.Duration(nil), buckets...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	histogram := make(map[time.Duration]int, len(bounds))
+	for _, b := range bounds {
+		histogram[b] = 0
+	}
+	if len(bounds) == 0 {
+		return histogram
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return histogram
+	}
+	now := c.now()
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		age := now.Sub(ent.Value.(*entry).updated)
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] >= age })
+		if i == len(bounds) {
+			i--
+		}
+		histogram[bounds[i]]++
+	}
+	return histogram
+}
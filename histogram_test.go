@@ -0,0 +1,45 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWindowAgeHistogram(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(20, time.Hour)
+	rl.now = clock.Now
+
+	// entries whose windows began 30, 20, 10 and 0 minutes ago, 2 of each
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 2; j++ {
+			rl.Incr(fmt.Sprintf("foo_%d_%d", i, j), 10)
+		}
+		if i < 3 {
+			clock.Add(10 * time.Minute)
+		}
+	}
+
+	buckets := []time.Duration{15 * time.Minute, time.Minute, 25 * time.Minute}
+	expected := map[time.Duration]int{
+		time.Minute:      2,
+		15 * time.Minute: 2,
+		25 * time.Minute: 4,
+	}
+	if histogram := rl.WindowAgeHistogram(buckets); !reflect.DeepEqual(histogram, expected) {
+		t.Fatalf("expected %v, got %v", expected, histogram)
+	}
+
+	// a key that resets its window moves back to the youngest bucket
+	rl.Reset("foo_0_0")
+	expected[time.Minute], expected[25*time.Minute] = 3, 3
+	if histogram := rl.WindowAgeHistogram(buckets); !reflect.DeepEqual(histogram, expected) {
+		t.Fatalf("expected %v after the reset, got %v", expected, histogram)
+	}
+
+	if histogram := rl.WindowAgeHistogram(nil); len(histogram) != 0 {
+		t.Fatalf("expected an empty histogram without buckets, got %v", histogram)
+	}
+}
@@ -0,0 +1,62 @@
+package ratelimiter
+
+import "time"
+
+// historyBucket is the count of increments a key had in a bucket starting at start
+type historyBucket struct {
+	start time.Time
+	count uint64
+}
+
+// WithHistory keeps a small ring of per key increment counts, one for each bucket long
+// slice of time, so CountSince can answer how many increments a key had since a given time.
+// Only the most recent size buckets are kept, so history reaches back at most bucket*size.
+// Each key holds up to size buckets, and every Incr reads the clock to pick the bucket it lands in.
+func WithHistory(bucket time.Duration, size int) Option {
+	return func(c *Cache) {
+		if bucket <= 0 || size <= 0 {
+			return
+		}
+		c.historyBucket = bucket
+		c.historySize = size
+	}
+}
+
+// recordHistory counts an increment in the entry's current history bucket
+func (c *Cache) recordHistory(e *entry) {
+	start := c.now().Truncate(c.historyBucket)
+	if n := len(e.history); n > 0 && e.history[n-1].start.Equal(start) {
+		e.history[n-1].count++
+		return
+	}
+
+	if len(e.history) >= c.historySize {
+		copy(e.history, e.history[1:])
+		e.history = e.history[:len(e.history)-1]
+	}
+	e.history = append(e.history, historyBucket{start, 1})
+}
+
+// CountSince returns how many increments key has had since the given time at the resolution of
+// the WithHistory bucket, a bucket that overlaps since is counted in full. Increments older than
+// the history that's kept aren't counted. Without WithHistory it always returns 0.
+func (c *Cache) CountSince(key interface{}, since time.Time) uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0
+	}
+	ent, ok := c.find(key)
+	if !ok {
+		return 0
+	}
+
+	var cnt uint64
+	for _, b := range ent.Value.(*entry).history {
+		if b.start.Add(c.historyBucket).After(since) {
+			cnt += b.count
+		}
+	}
+	return cnt
+}
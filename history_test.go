@@ -0,0 +1,51 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountSince(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	rl, _ := New(10, time.Minute, WithHistory(time.Second, 5))
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	// 1 increment at 0s, 2 at 1s, ... 6 at 5s, the 0s bucket falls out of the ring
+	for s := 0; s < 6; s++ {
+		now = start.Add(time.Duration(s) * time.Second)
+		for i := 0; i <= s; i++ {
+			_, _ = rl.Incr(key, 1000)
+		}
+	}
+
+	for _, tc := range []struct {
+		since time.Time
+		want  uint64
+	}{
+		{start, 20},
+		{start.Add(3 * time.Second), 15},
+		{start.Add(3500 * time.Millisecond), 15},
+		{start.Add(5 * time.Second), 6},
+		{start.Add(10 * time.Second), 0},
+	} {
+		if cnt := rl.CountSince(key, tc.since); cnt != tc.want {
+			t.Fatalf("expected [%d] increments since [%s] but got [%d]", tc.want, tc.since.Sub(start), cnt)
+		}
+	}
+
+	if cnt := rl.CountSince("bar", start); cnt != 0 {
+		t.Fatalf("expected [0] for a missing key but got [%d]", cnt)
+	}
+}
+
+func TestCountSinceWithoutHistory(t *testing.T) {
+	rl, _ := New(10, time.Minute)
+	_, _ = rl.Incr("foo", 10)
+
+	if cnt := rl.CountSince("foo", time.Time{}); cnt != 0 {
+		t.Fatalf("expected [0] without history but got [%d]", cnt)
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimiter
+
+import "time"
+
+// EntryInfo is everything the cache knows about a single key, as returned by Inspect.
+// Fields for features that aren't in use are left at their zero value.
+type EntryInfo struct {
+	Key interface{}
+
+	// Count is the key's count in the current rate period and Total its lifetime total
+	Count uint64
+	Total uint64
+
+	// WindowStart is when the current rate period began and ResetAt when it lifts,
+	// ResetAt is zero if it never does
+	WindowStart time.Time
+	ResetAt     time.Time
+
+	// Created is when the key was added to the cache
+	Created time.Time
+
+	// LastSeen is the time of the key's last increment, only tracked WithIntervalTracking
+	LastSeen time.Time
+
+	// Limit is the key's own limit from SetLimit
+	Limit int
+
+	// Protected is whether the key is exempt from capacity eviction, see Protect
+	Protected bool
+
+	// Bank is the credit saved up for IncrBanked
+	Bank uint64
+
+	// Inflight is the number of operations holding an Acquire on the key
+	Inflight int
+}
+
+// Inspect returns everything the cache knows about key, for debugging. It doesn't count as a use
+// of the key, so it isn't moved in the LRU.
+func (c *Cache) Inspect(key interface{}) (*EntryInfo, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return nil, false
+	}
+	ent, ok := c.find(key)
+	if !ok {
+		return nil, false
+	}
+
+	e := ent.Value.(*entry)
+	info := &EntryInfo{
+		Key:         e.key,
+		Count:       e.value,
+		Total:       e.total,
+		WindowStart: e.updated,
+		Created:     e.created,
+		LastSeen:    e.lastSeen,
+		Limit:       e.limit,
+		Protected:   c.isProtected(e.id),
+		Bank:        e.bank,
+		Inflight:    e.inflight,
+	}
+	if c.ratePeriod > 0 {
+		info.ResetAt = c.windowEnd(e)
+	}
+	return info, true
+}
@@ -0,0 +1,63 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second, WithIntervalTracking())
+	rl.now = clock.Now
+
+	if _, ok := rl.Inspect("key"); ok {
+		t.Fatalf("expected nothing for a key that isn't cached")
+	}
+
+	created := clock.Now()
+	rl.Incr("key", 5)
+	clock.Add(time.Second)
+	rl.Incr("key", 5)
+
+	info, ok := rl.Inspect("key")
+	if !ok {
+		t.Fatalf("expected the key to be found")
+	}
+	if info.Key != "key" || info.Count != 2 || info.Total != 2 {
+		t.Fatalf("unexpected counts [%d] [%d]", info.Count, info.Total)
+	}
+	if !info.WindowStart.Equal(created) || !info.Created.Equal(created) || !info.ResetAt.Equal(created.Add(10*time.Second)) {
+		t.Fatalf("unexpected window [%v] to [%v] for a key created [%v]", info.WindowStart, info.ResetAt, info.Created)
+	}
+	if !info.LastSeen.Equal(clock.Now()) {
+		t.Fatalf("expected the last increment to be [%v], got [%v]", clock.Now(), info.LastSeen)
+	}
+	if info.Limit != 0 || info.Protected || info.Inflight != 0 {
+		t.Fatalf("expected no limit, protection or inflight operations yet")
+	}
+
+	// a new period and the optional features
+	clock.Add(10 * time.Second)
+	for i := 0; i < 4; i++ {
+		rl.Incr("key", 1)
+	}
+	rl.SetLimit("key", 3)
+	rl.Protect("key")
+	release, _ := rl.Acquire("key", 2)
+	defer release()
+
+	info, _ = rl.Inspect("key")
+	if info.Count != 4 || info.Total != 6 || !info.WindowStart.Equal(clock.Now()) {
+		t.Fatalf("expected the new period to have started, got [%d] [%d] [%v]", info.Count, info.Total, info.WindowStart)
+	}
+	if info.Limit != 3 || !info.Protected || info.Inflight != 1 {
+		t.Fatalf("expected the limit, protection and inflight operation to show, got [%d] [%t] [%d]", info.Limit, info.Protected, info.Inflight)
+	}
+
+	// no reset time without a rate period
+	rl, _ = New(10, 0)
+	rl.Incr("key", 1)
+	if info, _ := rl.Inspect("key"); !info.ResetAt.IsZero() {
+		t.Fatalf("expected no reset time without a rate period, got [%v]", info.ResetAt)
+	}
+}
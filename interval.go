@@ -0,0 +1,44 @@
+package ratelimiter
+
+import "time"
+
+// WithIntervalTracking keeps an exponentially weighted moving average of the time between each key's
+// increments, weighted by EWMAWeight, so a controller outside the cache can read it with AvgInterval
+// and tighten or loosen limits for aggressive or quiet clients. Each Incr stamps the key with the
+// current time to measure the gap to the next one.
+func WithIntervalTracking() Option {
+	return func(c *Cache) {
+		c.trackIntervals = true
+	}
+}
+
+// recordInterval folds the time since the entry's last increment into its average
+func (c *Cache) recordInterval(e *entry) {
+	now := c.now()
+	if !e.lastSeen.IsZero() {
+		interval := now.Sub(e.lastSeen)
+		if e.avgInterval == 0 {
+			e.avgInterval = interval
+		} else {
+			weight := c.ewmaWeight()
+			e.avgInterval = time.Duration(weight*float64(interval) + (1-weight)*float64(e.avgInterval))
+		}
+	}
+	e.lastSeen = now
+}
+
+// AvgInterval returns the moving average of the time between key's increments. ok is false if the
+// key isn't cached, hasn't been incremented twice yet or the cache wasn't created WithIntervalTracking.
+func (c *Cache) AvgInterval(key interface{}) (avg time.Duration, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0, false
+	}
+	ent, ok := c.find(key)
+	if !ok || ent.Value.(*entry).avgInterval == 0 {
+		return 0, false
+	}
+	return ent.Value.(*entry).avgInterval, true
+}
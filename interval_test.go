@@ -0,0 +1,46 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAvgInterval(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Hour, WithIntervalTracking())
+	rl.now = clock.Now
+
+	key := "foo"
+	_, _ = rl.Incr(key, 1000)
+	if _, ok := rl.AvgInterval(key); ok {
+		t.Fatalf("expected no average interval after a single increment")
+	}
+
+	// a few slow increments first, then a steady 100ms that the average should converge on
+	for i := 0; i < 3; i++ {
+		clock.Add(5 * time.Second)
+		_, _ = rl.Incr(key, 1000)
+	}
+	for i := 0; i < 50; i++ {
+		clock.Add(100 * time.Millisecond)
+		_, _ = rl.Incr(key, 1000)
+	}
+
+	avg, ok := rl.AvgInterval(key)
+	if !ok {
+		t.Fatalf("expected an average interval for foo")
+	}
+	if avg < 95*time.Millisecond || avg > 105*time.Millisecond {
+		t.Fatalf("expected the average interval to converge near [100ms] got [%s]", avg)
+	}
+}
+
+func TestAvgIntervalDisabled(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("foo", 10)
+
+	if _, ok := rl.AvgInterval("foo"); ok {
+		t.Fatalf("expected no average interval without WithIntervalTracking")
+	}
+}
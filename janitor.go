@@ -0,0 +1,100 @@
+package ratelimiter
+
+import "time"
+
+// janitorState is the bookkeeping for the background sweeper, guarded by the cache lock
+type janitorState struct {
+	stop        chan struct{}
+	lastSweep   time.Time
+	lastRemoved int
+}
+
+// StartJanitor starts a background goroutine that removes entries whose rate period is over
+// every interval, rather than leaving them to be evicted once the cache fills up. Removed entries
+// go through OnEvicted like any other eviction. It does nothing with a ratePeriod of 0, since
+// entries never expire, or if the janitor is already running.
+func (c *Cache) StartJanitor(interval time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || c.janitor.stop != nil || c.ratePeriod <= 0 || interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	c.janitor.stop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sweep()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopJanitor stops the background goroutine started by StartJanitor, it's safe to call
+// when the janitor isn't running.
+func (c *Cache) StopJanitor() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopJanitor()
+}
+
+// stopJanitor is StopJanitor for callers that already hold the write lock
+func (c *Cache) stopJanitor() {
+	if c.janitor.stop != nil {
+		close(c.janitor.stop)
+		c.janitor.stop = nil
+	}
+}
+
+// JanitorStatus reports whether the janitor is running, when it last swept and how many
+// entries that sweep removed. lastSweep is zero if it hasn't swept yet.
+func (c *Cache) JanitorStatus() (running bool, lastSweep time.Time, lastRemoved int) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.janitor.stop != nil, c.janitor.lastSweep, c.janitor.lastRemoved
+}
+
+// sweep removes every entry whose rate period is over and returns how many it removed,
+// under a memory budget the period is shortened first if the cache is over it
+func (c *Cache) sweep() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.memoryBudget > 0 {
+		c.tuneExpiry()
+	}
+
+	now := c.now()
+	removed := 0
+	for ent := c.evictList.Back(); ent != nil; {
+		prev := ent.Prev()
+		if e := ent.Value.(*entry); c.sweepable(e, now) {
+			c.emit(EventExpired, e, e.value)
+			c.evict(ent)
+			removed++
+		}
+		ent = prev
+	}
+
+	c.janitor.lastSweep = now
+	c.janitor.lastRemoved = removed
+	return removed
+}
+
+// sweepable reports whether the janitor should remove the entry, which is when its rate period
+// is over, or when it's been idle for longer than a shortened expiry under a memory budget
+func (c *Cache) sweepable(e *entry, now time.Time) bool {
+	if c.expired(e, now) {
+		return true
+	}
+	return c.expiry > 0 && c.expiry < c.ratePeriod && now.Sub(e.updated) > c.expiry
+}
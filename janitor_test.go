@@ -0,0 +1,89 @@
+package ratelimiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock tests can move forward while background goroutines read it
+type fakeClock struct {
+	lock sync.Mutex
+	t    time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.t
+}
+
+func (f *fakeClock) Add(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.t = f.t.Add(d)
+}
+
+// waitForSweep polls until the janitor has swept at or after since
+func waitForSweep(t *testing.T, rl *Cache, since time.Time) (lastSweep time.Time, lastRemoved int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		_, lastSweep, lastRemoved = rl.JanitorStatus()
+		if !lastSweep.Before(since) {
+			return lastSweep, lastRemoved
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected the janitor to sweep within 2 seconds")
+	return
+}
+
+func TestJanitorStatus(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Minute)
+	rl.now = clock.Now
+
+	if running, lastSweep, _ := rl.JanitorStatus(); running || !lastSweep.IsZero() {
+		t.Fatalf("expected the janitor not to be running before it's started")
+	}
+
+	for _, key := range []string{"foo", "bar", "baz"} {
+		_, _ = rl.Incr(key, 10)
+	}
+
+	rl.StartJanitor(10 * time.Millisecond)
+	defer rl.StopJanitor()
+	if running, _, _ := rl.JanitorStatus(); !running {
+		t.Fatalf("expected the janitor to be running once started")
+	}
+
+	// nothing has expired yet
+	lastSweep, lastRemoved := waitForSweep(t, rl, clock.Now())
+	if lastRemoved != 0 {
+		t.Fatalf("expected nothing removed before the rate period is over, removed [%d]", lastRemoved)
+	}
+
+	clock.Add(30 * time.Second)
+	_, _ = rl.Incr("qux", 10)
+	clock.Add(31 * time.Second)
+
+	lastSweep, lastRemoved = waitForSweep(t, rl, clock.Now())
+	if !lastSweep.Equal(clock.Now()) {
+		t.Fatalf("expected last sweep at [%s] got [%s]", clock.Now(), lastSweep)
+	}
+	if lastRemoved != 3 {
+		t.Fatalf("expected the [3] expired keys to be removed, removed [%d]", lastRemoved)
+	}
+	if _, ok := rl.Get("qux"); !ok || rl.Len() != 1 {
+		t.Fatalf("expected only the unexpired key qux to be left, have [%d] keys", rl.Len())
+	}
+
+	rl.StopJanitor()
+	if running, _, _ := rl.JanitorStatus(); running {
+		t.Fatalf("expected the janitor to be stopped")
+	}
+}
@@ -0,0 +1,153 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+)
+
+// hashKey hashes any comparable key by value with FNV-1a, so keys that are == hash the same, and does
+// so the same in every process, since snapshots of hashed keys, staggered window offsets and StatsD
+// sampling all rely on it. Only keys holding pointers or channels, which hash by address, differ
+// between processes. The common key types are switched on to skip reflection.
+func hashKey(key interface{}) uint64 {
+	h := fnv.New64a()
+	switch k := key.(type) {
+	case string:
+		writeString(h, reflect.String, k)
+	case int:
+		writeUint(h, reflect.Int, uint64(k))
+	case int8:
+		writeUint(h, reflect.Int8, uint64(k))
+	case int16:
+		writeUint(h, reflect.Int16, uint64(k))
+	case int32:
+		writeUint(h, reflect.Int32, uint64(k))
+	case int64:
+		writeUint(h, reflect.Int64, uint64(k))
+	case uint:
+		writeUint(h, reflect.Uint, uint64(k))
+	case uint8:
+		writeUint(h, reflect.Uint8, uint64(k))
+	case uint16:
+		writeUint(h, reflect.Uint16, uint64(k))
+	case uint32:
+		writeUint(h, reflect.Uint32, uint64(k))
+	case uint64:
+		writeUint(h, reflect.Uint64, uint64(k))
+	default:
+		writeValue(h, reflect.ValueOf(key))
+	}
+	return h.Sum64()
+}
+
+// writeValue writes v to h tagged with its type, walking into structs, arrays and interfaces
+func writeValue(h hash.Hash64, v reflect.Value) {
+	if !v.IsValid() {
+		_, _ = h.Write([]byte{byte(reflect.Invalid)})
+		return
+	}
+	// named types are told apart from the builtin ones the fast path in hashKey tags by kind
+	writeString(h, reflect.Interface, v.Type().String())
+
+	switch v.Kind() {
+	case reflect.Bool:
+		var b uint64
+		if v.Bool() {
+			b = 1
+		}
+		writeUint(h, reflect.Bool, b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(h, v.Kind(), uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, v.Kind(), v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(h, v.Kind(), v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(h, v.Kind(), real(v.Complex()))
+		writeFloat(h, v.Kind(), imag(v.Complex()))
+	case reflect.String:
+		writeString(h, reflect.String, v.String())
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		writeUint(h, v.Kind(), uint64(v.Pointer()))
+	case reflect.Interface:
+		writeValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeValue(h, v.Field(i))
+		}
+	default:
+		panic("ratelimiter: hash of unhashable key type " + v.Type().String())
+	}
+}
+
+func writeUint(h hash.Hash64, kind reflect.Kind, n uint64) {
+	var buf [9]byte
+	buf[0] = byte(kind)
+	binary.LittleEndian.PutUint64(buf[1:], n)
+	_, _ = h.Write(buf[:])
+}
+
+// writeFloat writes f with -0 folded into 0, since the two are ==
+func writeFloat(h hash.Hash64, kind reflect.Kind, f float64) {
+	if f == 0 {
+		f = 0
+	}
+	writeUint(h, kind, math.Float64bits(f))
+}
+
+// writeString writes s prefixed with its length, so consecutive strings can't run together
+func writeString(h hash.Hash64, kind reflect.Kind, s string) {
+	writeUint(h, kind, uint64(len(s)))
+	_, _ = io.WriteString(h, s)
+}
+
+// WithKeyHashing stores entries under a 64 bit hash of their key rather than the key itself, to save
+// memory when keys are large, like full URLs. Two keys sharing a hash count as one, which is rare at
+// 64 bits but not impossible. With keepKeys the original key is still kept on the entry, so Keys,
+// OnEvicted and the rest report it as usual, and a collision is spotted and passed to OnKeyCollision.
+// Without it the key is dropped, everything that reports keys reports the hash as a uint64 instead,
+// and collisions go unnoticed. Either way every method still takes the original key.
+func WithKeyHashing(keepKeys bool) Option {
+	return func(c *Cache) {
+		c.hashKeys = true
+		c.keepKeys = keepKeys
+	}
+}
+
+// id returns what key is stored under in the map
+func (c *Cache) id(key interface{}) interface{} {
+	if !c.hashKeys {
+		return key
+	}
+	return hashKey(key)
+}
+
+// storedID returns the map id for a key as it's stored on an entry, which is already the hash when
+// keys are dropped
+func (c *Cache) storedID(key interface{}) interface{} {
+	if c.hashKeys && !c.keepKeys {
+		return key
+	}
+	return c.id(key)
+}
+
+// find returns the list element for key, reporting a collision if it's stored under the same
+// hash as a different key
+func (c *Cache) find(key interface{}) (*list.Element, bool) {
+	ent, ok := c.cache[c.id(key)]
+	if ok && c.keepKeys && c.OnKeyCollision != nil {
+		if existing := ent.Value.(*entry).key; existing != key {
+			c.OnKeyCollision(key, existing)
+		}
+	}
+	return ent, ok
+}
@@ -0,0 +1,140 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyHashingMemory(t *testing.T) {
+	plain, _ := New(1000, time.Hour)
+	hashed, _ := New(1000, time.Hour, WithKeyHashing(false))
+
+	prefix := "https://example.com/" + strings.Repeat("path/", 50)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("%s%d", prefix, i)
+		plain.Incr(key, 10)
+		hashed.Incr(key, 10)
+	}
+
+	if p, h := plain.ApproxMemoryBytes(), hashed.ApproxMemoryBytes(); h >= p-int64(1000*len(prefix)) {
+		t.Fatalf("expected hashing to save the key lengths, have [%d] hashed vs [%d] plain", h, p)
+	}
+
+	// every method still takes the original key
+	key := prefix + "7"
+	if value, ok := hashed.Incr(key, 10); !ok || value != 2 {
+		t.Fatalf("expected the hashed key to be found again, got [%d]", value)
+	}
+	if !hashed.Contains(key) {
+		t.Fatalf("expected Contains to find the hashed key")
+	}
+	hashed.Remove(key)
+	if hashed.Contains(key) || hashed.Len() != 999 {
+		t.Fatalf("expected the hashed key to be removed")
+	}
+	if _, ok := hashed.Keys()[0].(uint64); !ok {
+		t.Fatalf("expected dropped keys to be reported as their hash")
+	}
+}
+
+func TestKeyHashingCollisions(t *testing.T) {
+	n := 20000
+	rl, _ := New(n, time.Hour, WithKeyHashing(true))
+
+	var collisions int
+	rl.OnKeyCollision = func(key interface{}, existing interface{}) {
+		collisions++
+	}
+	for i := 0; i < n; i++ {
+		rl.Incr(fmt.Sprintf("user_%d", i), 10)
+		rl.Incr(fmt.Sprintf("user_%d", i), 10)
+	}
+	if collisions != 0 || rl.Len() != n {
+		t.Fatalf("expected [%d] distinct keys without collisions, have [%d] keys and [%d] collisions", n, rl.Len(), collisions)
+	}
+	if rl.Keys()[0] != "user_0" {
+		t.Fatalf("expected the original keys to be kept, got [%v]", rl.Keys()[0])
+	}
+
+	// force a collision by storing a key under another's hash
+	ent, _ := rl.find("user_0")
+	ent.Value.(*entry).key = "someone_else"
+	rl.Incr("user_0", 10)
+	if collisions != 1 {
+		t.Fatalf("expected the collision to be reported, have [%d]", collisions)
+	}
+}
+
+// keys are hashed by value, not by how they print
+func TestKeyHashingByValue(t *testing.T) {
+	type user struct{ name string }
+	rl, _ := New(10, time.Hour, WithKeyHashing(true))
+
+	var collisions int
+	rl.OnKeyCollision = func(key interface{}, existing interface{}) {
+		collisions++
+	}
+	a, b := &user{"foo"}, &user{"foo"}
+	rl.Incr(a, 10)
+	rl.Incr(b, 10)
+	rl.Incr(1, 10)
+	rl.Incr("1", 10)
+	if rl.Len() != 4 || collisions != 0 {
+		t.Fatalf("expected keys that print the same to be kept apart, have [%d] keys and [%d] collisions", rl.Len(), collisions)
+	}
+	if value, _ := rl.Incr(user{"foo"}, 10); value != 1 {
+		t.Fatalf("expected a new struct key to start at [1], got [%d]", value)
+	}
+	if value, _ := rl.Incr(user{"foo"}, 10); value != 2 {
+		t.Fatalf("expected an equal struct key to hash the same, got [%d]", value)
+	}
+
+	rl.Incr("user:1:login", 10)
+	if got := rl.MatchCounts("user:*"); got["user:1:login"] != 1 {
+		t.Fatalf("expected MatchCounts to match hashed keys by their original key, got %v", got)
+	}
+}
+
+// hashes are persisted by Save and used to stagger windows, so they mustn't change between processes
+func TestHashKeyStable(t *testing.T) {
+	type pair struct{ a, b string }
+	if h := hashKey("user:1"); h != 8550828264287687959 {
+		t.Fatalf("expected a string key's hash to be fixed, got [%d]", h)
+	}
+	if h := hashKey(42); h != 2449347354575781711 {
+		t.Fatalf("expected an int key's hash to be fixed, got [%d]", h)
+	}
+	if hashKey(pair{"ab", ""}) == hashKey(pair{"a", "b"}) {
+		t.Fatalf("expected struct fields not to run together")
+	}
+	if hashKey(pair{"a", "b"}) != hashKey(pair{"a", "b"}) {
+		t.Fatalf("expected equal struct keys to hash the same")
+	}
+
+	type userID string
+	if hashKey(userID("user:1")) == hashKey("user:1") {
+		t.Fatalf("expected a named type to hash apart from its underlying type")
+	}
+}
+
+// a snapshot of hashed keys loads back under the same hashes
+func TestKeyHashingSaveLoad(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithKeyHashing(false))
+	rl.Incr("foo", 10)
+	rl.Incr("foo", 10)
+	var snap bytes.Buffer
+	if err := rl.Save(&snap); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+
+	restored, _ := New(10, time.Hour, WithKeyHashing(false))
+	if err := restored.Load(&snap); err != nil {
+		t.Fatalf("unable to load cache: %v", err)
+	}
+	if value, ok := restored.Incr("foo", 10); !ok || value != 3 {
+		t.Fatalf("expected the restored count to be found by the original key, got [%d]", value)
+	}
+}
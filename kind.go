@@ -0,0 +1,45 @@
+package ratelimiter
+
+// Kind separates the operations counted against a single key by IncrKind
+type Kind int
+
+const (
+	// Read counts reads against a key
+	Read Kind = iota
+	// Write counts writes against a key
+	Write
+
+	numKinds = iota
+)
+
+// IncrKind increments the kind sub-counter for key, checking it against its own max so reads and
+// writes can be metered separately under one key without a second cache. Both kinds share the
+// key's rate period, once it's over the first kind to go over its max starts a new period for both.
+// Kind counts are kept apart from the count Incr uses.
+func (c *Cache) IncrKind(key interface{}, kind Kind, max int) (uint64, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed || kind < 0 || kind >= numKinds {
+		return 0, false
+	}
+
+	e, ok := c.admit(key)
+	if !ok {
+		return 0, false
+	}
+	e.kinds[kind]++
+	if e.kinds[kind] <= uint64(max) {
+		return e.kinds[kind], true
+	}
+
+	now := c.now()
+	if !c.expired(e, now) {
+		return e.kinds[kind], false
+	}
+
+	c.rollover(e, key, e.value, now)
+	e.value = 0
+	e.kinds[kind] = 1
+	return e.kinds[kind], true
+}
@@ -0,0 +1,96 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncrKind(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	key := "foo"
+	maxReads, maxWrites := 5, 2
+
+	for i := 0; i < maxWrites; i++ {
+		if _, ok := rl.IncrKind(key, Write, maxWrites); !ok {
+			t.Fatalf("expected write [%d] to be allowed", i+1)
+		}
+	}
+	if _, ok := rl.IncrKind(key, Write, maxWrites); ok {
+		t.Fatalf("expected writes over [%d] to be blocked", maxWrites)
+	}
+
+	// writes being blocked doesn't affect reads
+	for i := 0; i < maxReads; i++ {
+		cnt, ok := rl.IncrKind(key, Read, maxReads)
+		if !ok || cnt != uint64(i+1) {
+			t.Fatalf("expected read [%d] to be allowed, got count [%d]", i+1, cnt)
+		}
+	}
+	if _, ok := rl.IncrKind(key, Read, maxReads); ok {
+		t.Fatalf("expected reads over [%d] to be blocked", maxReads)
+	}
+
+	// once the shared period is over both kinds start fresh
+	clock.Add(11 * time.Second)
+	if cnt, ok := rl.IncrKind(key, Read, maxReads); !ok || cnt != 1 {
+		t.Fatalf("expected reads to start over after the period, got count [%d]", cnt)
+	}
+	if cnt, ok := rl.IncrKind(key, Write, maxWrites); !ok || cnt != 1 {
+		t.Fatalf("expected writes to start over with the shared period, got count [%d]", cnt)
+	}
+
+	if rl.Len() != 1 {
+		t.Fatalf("expected reads and writes to share one entry, have [%d]", rl.Len())
+	}
+}
+
+func TestIncrKindReset(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	rl.IncrKind("foo", Write, 1)
+	if _, ok := rl.IncrKind("foo", Write, 1); ok {
+		t.Fatalf("expected the second write to be blocked")
+	}
+
+	rl.Reset("foo")
+	if cnt, ok := rl.IncrKind("foo", Write, 1); !ok || cnt != 1 {
+		t.Fatalf("expected Reset to clear the kind counts, got count [%d]", cnt)
+	}
+}
+
+// IncrKind starting the shared period over doesn't carry a blocked Incr count into it
+func TestIncrKindRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Minute)
+	rl.now = clock.Now
+
+	var previous uint64
+	rl.SetResetHandler("foo", func(p uint64) {
+		previous = p
+	})
+	for i := 0; i < 5; i++ {
+		rl.Incr("foo", 2)
+	}
+	rl.IncrKind("foo", Write, 1)
+	rl.IncrKind("foo", Write, 1)
+
+	clock.Add(2 * time.Minute)
+	if cnt, ok := rl.IncrKind("foo", Write, 1); !ok || cnt != 1 {
+		t.Fatalf("expected the write to start the new period, got count [%d]", cnt)
+	}
+	if _, ok := rl.Incr("foo", 2); !ok {
+		t.Fatalf("expected Incr to be allowed in the period IncrKind started")
+	}
+	if previous != 5 || rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be reported with the finished count, got [%d]", previous)
+	}
+}
+
+func TestIncrKindUnknown(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+	if _, ok := rl.IncrKind("foo", Kind(7), 10); ok {
+		t.Fatalf("expected an unknown kind to be rejected")
+	}
+}
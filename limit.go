@@ -0,0 +1,35 @@
+package ratelimiter
+
+// SetLimit gives key its own limit that Incr uses in place of the maxValue it's passed, for keys
+// that need more or less than everyone else. The key is added to the cache if it isn't there
+// already, and the limit lives with the entry so it's dropped if the key is evicted or removed.
+// A maxValue of 0 or less clears it.
+func (c *Cache) SetLimit(key interface{}, maxValue int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return
+	}
+	if maxValue < 0 {
+		maxValue = 0
+	}
+	c.lookup(key).limit = maxValue
+}
+
+// EffectiveLimit returns the limit the next Incr on key would be checked against, which is its
+// SetLimit limit adjusted for the adaptive limit and any warmup still in progress. ok is false if the
+// key isn't cached or has no limit of its own, since then the limit depends on the maxValue passed to Incr.
+func (c *Cache) EffectiveLimit(key interface{}) (limit int, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0, false
+	}
+	ent, ok := c.find(key)
+	if !ok || ent.Value.(*entry).limit <= 0 {
+		return 0, false
+	}
+	return c.effectiveLimit(ent.Value.(*entry), 0), true
+}
@@ -0,0 +1,63 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetLimit(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	rl.SetLimit("vip", 5)
+	for i := 1; i <= 6; i++ {
+		_, underRateLimit := rl.Incr("vip", 2)
+		if underRateLimit != (i <= 5) {
+			t.Fatalf("expected the per key limit of [5] to override maxValue at increment [%d]", i)
+		}
+	}
+
+	if limit, ok := rl.EffectiveLimit("vip"); !ok || limit != 5 {
+		t.Fatalf("expected an effective limit of [5] got [%d]", limit)
+	}
+	if _, ok := rl.EffectiveLimit("other"); ok {
+		t.Fatalf("expected no effective limit for a key without its own limit")
+	}
+
+	rl.SetLimit("vip", 0)
+	if _, ok := rl.EffectiveLimit("vip"); ok {
+		t.Fatalf("expected clearing the limit to drop it")
+	}
+}
+
+func TestEffectiveLimitDuringWarmup(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Hour, WithWarmup(10*time.Second, 0.2))
+	rl.now = clock.Now
+
+	key := "foo"
+	rl.SetLimit(key, 100)
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 20},
+		{5 * time.Second, 60},
+		{10 * time.Second, 100},
+	} {
+		clock.Add(step.advance)
+		limit, ok := rl.EffectiveLimit(key)
+		if !ok || limit != step.want {
+			t.Fatalf("expected an effective limit of [%d] got [%d]", step.want, limit)
+		}
+
+		// bring the count up to the reported limit, the next Incr is the first that should be denied
+		rl.Swap(key, uint64(limit)-1)
+		if _, underRateLimit := rl.Incr(key, 1); !underRateLimit {
+			t.Fatalf("expected an Incr reaching the effective limit [%d] to be allowed", limit)
+		}
+		if _, underRateLimit := rl.Incr(key, 1); underRateLimit {
+			t.Fatalf("expected an Incr past the effective limit [%d] to be denied", limit)
+		}
+	}
+}
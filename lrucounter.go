@@ -17,12 +17,15 @@ package ratelimiter
 import (
 	"container/list"
 	"errors"
-	"sync"
 	"time"
 )
 
 // Cache is an LRU cache. It is safe for concurrent access as it locks when mutations are made
 // even with locks it's able to do 3.2MM ops per second on a standard laptop.
+//
+// A nil key is never stored. Methods that increment report a nil key as over the rate limit,
+// lookups report it as missing and anything else that's given one does nothing. Note a typed
+// nil, like a nil *T, isn't a nil interface and is kept like any other key.
 type Cache struct {
 
 	// MaxEntries is the maximum number of cache entries before
@@ -30,105 +33,569 @@ type Cache struct {
 	MaxEntries int
 
 	// OnEvicted optionally specificies a callback function to be
-	// executed when an entry is purged from the cache.
+	// executed when an entry is purged from the cache. value is the
+	// entry's uint64 count, or what ValueTransformer made of it.
 	OnEvicted func(key interface{}, value interface{})
 
+	// ValueTransformer optionally turns an entry's count into the value
+	// OnEvicted receives, e.g. to round it or attach metadata.
+	ValueTransformer func(key interface{}, value uint64) interface{}
+
+	// EWMAWeight is how much weight AllowEWMA gives the most recent rate period
+	// when averaging, between 0 and 1. Zero means use DefaultEWMAWeight.
+	EWMAWeight float64
+
+	// AnomalyFactor is how many times its baseline a key's count in a rate period has to reach
+	// before OnAnomaly is called. Zero means use DefaultAnomalyFactor.
+	AnomalyFactor float64
+
+	// OnAnomaly optionally specifies a callback function to be executed when a key's
+	// count in the current rate period jumps well above its usual count, see Anomaly.
+	// It runs once the cache lock is released, so it may call back into the cache,
+	// but the Incr that set it off doesn't return until it's done.
+	OnAnomaly func(a Anomaly)
+
+	// OnViolation optionally specifies a callback function to be
+	// executed when an Incr puts a key over its rate limit. Like OnAnomaly
+	// it runs after the lock is released and before that Incr returns.
+	OnViolation func(v Violation)
+
+	// OnAudit optionally specifies a callback function to be executed each
+	// time a key's rate period rolls over, with the details of the finished one.
+	// It runs after the lock is released, so slow writes to an audit log only hold
+	// up the call that rolled the period over, not everyone else using the cache.
+	OnAudit func(r AuditRecord)
+
+	// OnStoreError optionally specifies a callback function to be executed
+	// when the Store behind Incr returns an error, see WithStore.
+	OnStoreError func(key interface{}, err error)
+
+	// OnKeyCollision optionally specifies a callback function to be executed when a
+	// hashed key turns out to share its hash with a different key, see WithKeyHashing.
+	OnKeyCollision func(key interface{}, existing interface{})
+
 	// how long of a period of time does the rate limit apply
 	ratePeriod time.Duration
 
+	// backend Incr goes to in place of the in memory entries, and what to do when it fails, see WithStore
+	store       Store
+	degradeMode DegradeMode
+
+	// store keys by their hash and whether to keep the original on the entry, see WithKeyHashing
+	hashKeys bool
+	keepKeys bool
+
+	// scales every limit by downstream health, see WithAdaptiveLimit
+	adaptive *adaptiveState
+
+	// the distinct items each key may add per rate period with AddUnique, see WithUniqueLimit
+	uniqueLimit int
+
+	// per key increment history for CountSince, see WithHistory
+	historyBucket time.Duration
+	historySize   int
+
+	// warmup is how long a new key takes to ramp up to its full limit from warmupFloor
+	warmup      time.Duration
+	warmupFloor float64
+
+	// policy decides which entry is evicted first, see WithEvictionPolicy
+	policy EvictionPolicy
+
+	// when an insert takes the cache to highWatermark entries it's trimmed down to lowWatermark,
+	// see WithWatermarks
+	highWatermark int
+	lowWatermark  int
+
+	// zeroPeriodMode is what a ratePeriod of 0 means, see WithZeroPeriodMode
+	zeroPeriodMode ZeroPeriodMode
+
+	// minWindow is the least time a rate period lasts from when it started, see WithMinWindow
+	minWindow time.Duration
+
+	// trackIntervals keeps an average of the time between each key's increments, see WithIntervalTracking
+	trackIntervals bool
+
+	// granularity rate periods start on, zero means they start the instant a key is incremented
+	granularity time.Duration
+
+	// rejectWhenFull declines new keys instead of evicting, see WithRejectWhenFull
+	rejectWhenFull bool
+
+	// outstanding checkpoints oldest first and the last id handed out, see Checkpoint
+	checkpoints    []checkpoint
+	nextCheckpoint CheckpointID
+
+	// request sizes across every key, for AllowBySize
+	sizes sizeSketch
+
+	// escalating blocks for repeat offenders, see WithPenaltyBox
+	penalty *penaltyBox
+
+	// staggered offsets each key's granularity boundaries by a hash of the key, see WithStaggeredAlignment
+	staggered bool
+
 	evictList *list.List
 	cache     map[interface{}]*list.Element
 
-	lock sync.RWMutex
+	// protected keys are skipped when evicting for capacity, see Protect
+	protected map[interface{}]struct{}
+
+	// budgets are the shared counters for IncrShared, by budget group, in an LRU of their own
+	budgets     map[interface{}]*list.Element
+	budgetOrder *list.List
+
+	// groups maps a group to the keys that were incremented in it
+	groups map[interface{}]map[interface{}]struct{}
+
+	// cache wide counters
+	counters counters
+
+	// peakLen is the most entries the cache has held, see PeakStats
+	peakLen int
+
+	// rate measures cache wide increments per second, see WithRateTracking
+	rate *rateMeter
+
+	// evictQueue delivers OnEvicted callbacks from a pool of workers, see WithAsyncEviction
+	evictQueue *evictQueue
+
+	// everSeen holds every key ever added to the cache, see WithEverSeen
+	everSeen *bloom
+
+	// blocked is the keys most recently denied by Incr, see WithBlockedHistory
+	blocked *blockedList
+
+	// evictionSample is a random sample of evicted entries, see WithEvictionSampling
+	evictionSample *reservoir
+
+	// background metrics, see StartMetricsExport
+	metricsStop chan struct{}
+
+	// background JSON lines event stream, see StreamEvents
+	stream *eventStream
+
+	// background StatsD export, see StartStatsDExport
+	statsdStop chan struct{}
+
+	// memoryBudget is the approximate size in bytes the janitor tries to keep the cache under
+	// by shortening expiry, see WithMemoryBudget
+	memoryBudget int64
+	expiry       time.Duration
+
+	// background expiry, see StartJanitor
+	janitor janitorState
+
+	lock cacheLock
+
+	// closed is set by Close
+	closed bool
+
+	// now returns the current time, tests swap it out to control the clock
+	now func() time.Time
+
+	// coarse clock state, see WithCoarseClock
+	clock *coarseClock
+}
+
+// counters are cache wide totals, guarded by the cache lock
+type counters struct {
+	// evictions counts entries removed for capacity or by the janitor
+	evictions uint64
+	// violations counts increments that were over the rate limit
+	violations uint64
+	// increments counts every increment through Incr and the methods built on it
+	increments uint64
+	// resets counts rate periods rolling over and counts reset by Reset and the like
+	resets uint64
 }
 
 type entry struct {
+	// id is what the entry is stored under in the map, the key itself unless keys are hashed
+	id interface{}
+
 	key   interface{}
 	value uint64
 	// stores the time that the entry was first incremented
 	updated time.Time
+	// started is when the current rate period actually began, updated may be rounded down from it
+	started time.Time
+	// created is when the key was added to the cache, zero if it's unknown
+	created time.Time
+	// total is every increment the key has ever had, it isn't reset with the rate period
+	total uint64
+
+	// limit overrides the maxValue passed to Incr when it's positive, see SetLimit
+	limit int
+
+	// bank is credit saved up from quiet rate periods for IncrBanked
+	bank uint64
+
+	// onReset is called when Incr starts a new rate period for the key, see SetResetHandler
+	onReset func(previous uint64)
+
+	// distinct child keys seen this rate period by IncrDistinctChild
+	children map[interface{}]struct{}
+
+	// bloom filter of the items added this rate period by AddUnique
+	unique *bloom
+
+	// per Kind counts for IncrKind
+	kinds [numKinds]uint64
+
+	// groups the key was incremented in with IncrInGroup
+	groups []interface{}
+
+	// inflight is the number of operations currently holding an Acquire on the key
+	inflight int
+
+	// available is closed by availableTimer when the rate period lifts, see AvailableAfter
+	available      chan struct{}
+	availableTimer *time.Timer
+
+	// history holds the most recent historyBucket long counts, oldest first
+	history []historyBucket
+
+	// lastSeen is the time of the last increment and avgInterval the moving average
+	// of the time between increments, for AvgInterval
+	lastSeen    time.Time
+	avgInterval time.Duration
+
+	// remote is the key's count on each other node merged in by MergeCRDT, and remoteTotal their sum
+	remote      map[string]uint64
+	remoteTotal uint64
+
+	// sizes is the key's request sizes for AllowBySize
+	sizes *sizeSketch
+
+	// penaltyLevel is how many times in a row the key has been put in the penalty box
+	// and penaltyUntil when the current block ends, see WithPenaltyBox
+	penaltyLevel int
+	penaltyUntil time.Time
+
+	// state for anomaly detection, see observeAnomaly
+	anomaly anomalyState
+
+	// state for AllowEWMA, kept apart from value so the two don't interfere
+	ewma       float64
+	ewmaCount  uint64
+	ewmaWindow time.Time
+}
+
+// KeyCount pairs a key with its count
+type KeyCount struct {
+	Key   interface{}
+	Count uint64
+}
+
+// Option configures a Cache when it's created with New
+type Option func(*Cache)
+
+// WithGranularity rounds the start of every rate period down to a multiple of d, so all keys
+// first incremented within the same d sized bucket share window boundaries and reset together.
+// Zero or a negative d keeps the default of starting the period the instant a key is incremented.
+func WithGranularity(d time.Duration) Option {
+	return func(c *Cache) {
+		c.granularity = d
+	}
+}
+
+// WithStaggeredAlignment shifts each key's WithGranularity boundaries by an offset within d taken
+// from a hash of the key, so keys that would all reset on the same instant are spread out over d
+// instead. Unlike jitter the offset is the same every time for a given key. It has no effect
+// without WithGranularity.
+func WithStaggeredAlignment() Option {
+	return func(c *Cache) {
+		c.staggered = true
+	}
 }
 
 // New creates a new Cache.
 // ratePeriod is the window between now and seconds ago the rate limit applies
-func New(maxEntries int, ratePeriod time.Duration) (*Cache, error) {
+func New(maxEntries int, ratePeriod time.Duration, opts ...Option) (*Cache, error) {
 	if maxEntries <= 0 {
 		return nil, errors.New("Must provide a positive size")
 	}
-	return &Cache{
+	c := &Cache{
 		MaxEntries: maxEntries,
 		evictList:  list.New(),
 		cache:      make(map[interface{}]*list.Element),
 		ratePeriod: ratePeriod,
-	}, nil
+		now:        timeNow,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// timeNow is the default clock for a Cache
+func timeNow() time.Time {
+	return time.Now().UTC()
+}
+
+// startWindow begins a new rate period for the entry at now
+func (c *Cache) startWindow(e *entry, now time.Time) {
+	e.updated = c.windowStart(e.id, now)
+	e.started = now
+}
+
+// windowStart returns when a rate period beginning at now starts, taking granularity into account
+func (c *Cache) windowStart(key interface{}, now time.Time) time.Time {
+	if c.granularity <= 0 {
+		return now
+	}
+	if c.staggered {
+		offset := time.Duration(hashKey(key) % uint64(c.granularity))
+		return now.Add(-offset).Truncate(c.granularity).Add(offset)
+	}
+	return now.Truncate(c.granularity)
 }
 
 // Incr allows you to increment a key, if it's over the rate limit maxValue and it's been shorter
 // than the grace period then it will return false for the underRateLimit boolean
 func (c *Cache) Incr(key interface{}, maxValue int) (uint64, bool) {
+	if c.store != nil {
+		return c.storeIncr(key, maxValue)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.incr(key, maxValue)
+}
+
+// IncrFull increments key the same as Incr, returning the key's lifetime total along with
+// its count for the current rate period, both read under the same lock.
+func (c *Cache) IncrFull(key interface{}, maxValue int) (windowed uint64, lifetime uint64, underLimit bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	if key == nil || c.closed {
+		return 0, 0, false
+	}
+	windowed, underLimit = c.incr(key, maxValue)
+	ent, ok := c.find(key)
+	if !ok {
+		// declined by WithRejectWhenFull
+		return windowed, 0, underLimit
+	}
+	return windowed, ent.Value.(*entry).total, underLimit
+}
+
+// incr is Incr for callers that already hold the write lock
+func (c *Cache) incr(key interface{}, maxValue int) (uint64, bool) {
+	if key == nil || c.closed {
+		return 0, false
+	}
+
 	underRateLimit := true
 
-	// check to make sure we have space, if not purge the oldest item
-	if c.evictList.Len() > c.MaxEntries-1 {
-		c.removeOldest()
+	ee, ok := c.find(key)
+	if !ok {
+		if c.rejectWhenFull && c.evictList.Len() >= c.MaxEntries {
+			return 0, false
+		}
+
+		// new item
+		item := c.add(key)
+		item.value = 1
+		item.total = 1
+
+		c.incremented(item)
+		return item.value, underRateLimit
 	}
 
-	if ee, ok := c.cache[key]; ok {
-		c.evictList.MoveToFront(ee)
-		ee.Value.(*entry).value++
-		if ee.Value.(*entry).value > uint64(maxValue) {
-
-			// check to see if we're over our rate limit AND we're within the ratePeriod duration
-			// if so then fail the rate limit otherwise reset the times and values for the current period
-			if c.ratePeriod > 0 {
-				dur := time.Now().UTC().Sub(ee.Value.(*entry).updated)
-				if dur > c.ratePeriod {
-					ee.Value.(*entry).value = 1
-					ee.Value.(*entry).updated = time.Now().UTC()
-				} else {
-					underRateLimit = false
-				}
+	c.touch(ee)
+	e := ee.Value.(*entry)
+	e.value++
+	e.total++
+	maxValue = c.effectiveLimit(e, maxValue)
+	if e.value+e.remoteTotal > uint64(maxValue) {
+
+		// check to see if we're over our rate limit AND we're within the ratePeriod duration
+		// if so then fail the rate limit otherwise reset the times and values for the current period
+		now := c.now()
+		if c.ratePeriod > 0 {
+			if c.expired(e, now) {
+				// this increment belongs to the new period, the rest were the one that's over
+				c.rollover(e, key, e.value-1, now)
+				e.value = 1
 			} else {
 				underRateLimit = false
 			}
-
+		} else if c.zeroPeriodMode == ZeroPeriodBlock {
+			underRateLimit = false
 		}
+	}
 
-		return ee.Value.(*entry).value, underRateLimit
+	if c.penalty != nil && c.penalize(e, !underRateLimit) {
+		underRateLimit = false
+	}
 
-	} else {
-		// new item
-		item := &entry{key, uint64(1), time.Now().UTC()}
+	if !underRateLimit {
+		c.counters.violations++
+		if c.blocked != nil {
+			c.blocked.add(e.id, BlockedKey{e.key, e.value, c.now()})
+		}
+		if c.OnViolation != nil {
+			fn, v := c.OnViolation, c.newViolation(e, maxValue, c.now())
+			c.lock.after(func() { fn(v) })
+		}
+	}
 
-		entry := c.evictList.PushFront(item)
-		c.cache[key] = entry
+	c.incremented(e)
+	return e.value, underRateLimit
+}
 
-		return item.value, underRateLimit
+// rollover starts a new rate period for an entry whose last one is over, count is what the finished
+// period ended on. It clears what the entry tracks per period but leaves the count itself for the
+// caller to set.
+func (c *Cache) rollover(e *entry, key interface{}, count uint64, now time.Time) {
+	finished := AuditRecord{Key: key, Count: count, Start: e.updated, End: c.windowEnd(e), ResetAt: now}
+	e.kinds = [numKinds]uint64{}
+	e.children, e.unique = nil, nil
+	c.clearRemote(e)
+	c.startWindow(e, now)
+	c.windowReset(e, finished)
+}
+
+// windowReset runs the optional bookkeeping after incr has started a new rate period for an entry,
+// finished describes the period that just ended
+func (c *Cache) windowReset(e *entry, finished AuditRecord) {
+	c.counters.resets++
+	if e.onReset != nil {
+		e.onReset(finished.Count)
 	}
+	if c.OnAudit != nil {
+		fn := c.OnAudit
+		c.lock.after(func() { fn(finished) })
+	}
+	c.emit(EventReset, e, finished.Count)
+}
 
+// incremented runs the optional per increment bookkeeping after incr has counted an entry
+func (c *Cache) incremented(e *entry) {
+	c.counters.increments++
+	if c.OnAnomaly != nil {
+		c.observeAnomaly(e)
+	}
+	if c.historySize > 0 {
+		c.recordHistory(e)
+	}
+	if c.trackIntervals {
+		c.recordInterval(e)
+	}
+	if c.rate != nil {
+		c.rate.observe(c.now())
+	}
 }
 
-// Get looks up a key's value from the cache.
+// Get looks up a key's value from the cache, marking it as recently used.
 func (c *Cache) Get(key interface{}) (value uint64, ok bool) {
+	// moving the key to the front mutates the list, so this needs the write lock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.find(key); ok {
+		c.touch(ent)
+		return ent.Value.(*entry).value, true
+	}
+	return
+}
+
+// Peek looks up a key's value from the cache without marking it as recently used.
+func (c *Cache) Peek(key interface{}) (value uint64, ok bool) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	if ent, ok := c.cache[key]; ok {
-		c.evictList.MoveToFront(ent)
+	if c.closed {
+		return
+	}
+	if ent, ok := c.find(key); ok {
 		return ent.Value.(*entry).value, true
 	}
 	return
 }
 
+// Contains reports whether key is in the cache without marking it as recently used.
+func (c *Cache) Contains(key interface{}) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return false
+	}
+	_, ok := c.find(key)
+	return ok
+}
+
+// Keys returns the keys in the cache from oldest to newest.
+func (c *Cache) Keys() []interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return nil
+	}
+	keys := make([]interface{}, 0, c.evictList.Len())
+	for ent := c.evictList.Back(); ent != nil; ent = ent.Prev() {
+		keys = append(keys, ent.Value.(*entry).key)
+	}
+	return keys
+}
+
+// OldestN returns up to n of the least recently used entries, oldest first, without removing them
+// or marking them as used. These are the next entries to be evicted, so it's a way to persist them first.
+func (c *Cache) OldestN(n int) []KeyCount {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return nil
+	}
+	if n > c.evictList.Len() {
+		n = c.evictList.Len()
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	oldest := make([]KeyCount, 0, n)
+	for ent := c.evictList.Back(); ent != nil && len(oldest) < n; ent = ent.Prev() {
+		e := ent.Value.(*entry)
+		oldest = append(oldest, KeyCount{e.key, e.value})
+	}
+	return oldest
+}
+
+// Lifetime looks up the total number of increments a key has had since it was added
+// to the cache, unlike Get it keeps climbing across rate period resets.
+func (c *Cache) Lifetime(key interface{}) (total uint64, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.find(key); ok {
+		return ent.Value.(*entry).total, true
+	}
+	return
+}
+
 // Remove removes the provided key from the cache.
 func (c *Cache) Remove(key interface{}) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	if ent, ok := c.cache[key]; ok {
+	if c.closed {
+		return
+	}
+	if ent, ok := c.find(key); ok {
 		c.removeElement(ent)
 	}
 }
@@ -137,23 +604,175 @@ func (c *Cache) Remove(key interface{}) {
 func (c *Cache) Len() int {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0
+	}
 	return c.evictList.Len()
 }
 
-// removeOldest removes the oldest item from the cache.
+// lookup returns the entry for key moving it to the front, if the key isn't cached
+// yet an empty entry is added for it, evicting the oldest item if we're out of space
+func (c *Cache) lookup(key interface{}) *entry {
+	if ee, ok := c.find(key); ok {
+		c.touch(ee)
+		return ee.Value.(*entry)
+	}
+	return c.add(key)
+}
+
+// touch marks a list element as recently used, which under FIFO eviction does nothing
+func (c *Cache) touch(e *list.Element) {
+	if c.policy == LRU {
+		c.evictList.MoveToFront(e)
+	}
+}
+
+// add inserts an empty entry for a key that isn't cached yet, evicting the oldest items if we're out of space
+func (c *Cache) add(key interface{}) *entry {
+	// check to make sure we have space, if not purge the oldest item
+	if c.evictList.Len() > c.MaxEntries-1 {
+		c.removeOldest()
+	}
+
+	now := c.now()
+	if c.everSeen != nil {
+		c.everSeen.add(key)
+	}
+	item := &entry{id: c.id(key), key: key, created: now}
+	if c.hashKeys && !c.keepKeys {
+		item.key = item.id
+	}
+	c.startWindow(item, now)
+	ent := c.evictList.PushFront(item)
+	c.cache[item.id] = ent
+	if n := c.evictList.Len(); n > c.peakLen {
+		c.peakLen = n
+	}
+
+	// the trim is best effort, it stops rather than evict protected entries or the one just added
+	if c.highWatermark > 0 && c.evictList.Len() >= c.highWatermark {
+		for c.evictList.Len() > c.lowWatermark {
+			if !c.removeOldestUnprotected(ent) {
+				break
+			}
+		}
+	}
+	return item
+}
+
+// WouldEvict reports how many of the oldest entries would be evicted to make room
+// if newKeys brand new keys were inserted right now
+func (c *Cache) WouldEvict(newKeys int) int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	// the oldest go first, so unprotected entries are evicted before any of the new keys, which are
+	// evicted before protected entries
+	protected := 0
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		if c.isProtected(ent.Value.(*entry).id) {
+			protected++
+		}
+	}
+	unprotected, added, size, evicted := c.evictList.Len()-protected, 0, c.evictList.Len(), 0
+
+	// evictUnprotected follows removeOldestUnprotected, the key being inserted isn't in added yet
+	evictUnprotected := func() bool {
+		switch {
+		case unprotected > 0:
+			unprotected--
+			evicted++
+		case added > 0:
+			added--
+		default:
+			return false
+		}
+		size--
+		return true
+	}
+	for i := 0; i < newKeys; i++ {
+		if size > c.MaxEntries-1 {
+			if c.rejectWhenFull {
+				// the rest are declined rather than evicting anything
+				break
+			}
+			// removeOldest falls back to a protected entry when there's nothing else
+			if !evictUnprotected() && protected > 0 {
+				protected--
+				evicted++
+				size--
+			}
+		}
+		size++
+		if c.highWatermark > 0 && size >= c.highWatermark {
+			for size > c.lowWatermark {
+				if !evictUnprotected() {
+					break
+				}
+			}
+		}
+		added++
+	}
+	return evicted
+}
+
+// removeOldestUnprotected evicts the oldest entry that isn't protected or keep, and reports
+// whether there was one
+func (c *Cache) removeOldestUnprotected(keep *list.Element) bool {
+	ent := c.evictList.Back()
+	for ent != nil && (ent == keep || c.isProtected(ent.Value.(*entry).id)) {
+		ent = ent.Prev()
+	}
+	if ent == nil {
+		return false
+	}
+	c.emit(EventEvicted, ent.Value.(*entry), ent.Value.(*entry).value)
+	c.evict(ent)
+	return true
+}
+
+// removeOldest removes the oldest unprotected item from the cache. If every item is
+// protected the oldest is removed anyway so the cache never grows past MaxEntries.
 func (c *Cache) removeOldest() {
 	ent := c.evictList.Back()
+	for ent != nil && c.isProtected(ent.Value.(*entry).id) {
+		ent = ent.Prev()
+	}
+	if ent == nil {
+		ent = c.evictList.Back()
+	}
 	if ent != nil {
-		c.removeElement(ent)
+		c.emit(EventEvicted, ent.Value.(*entry), ent.Value.(*entry).value)
+		c.evict(ent)
+	}
+}
+
+// evict removes an entry the cache chose to drop, as opposed to one the caller asked to remove
+func (c *Cache) evict(e *list.Element) {
+	c.counters.evictions++
+	if c.evictionSample != nil {
+		c.evictionSample.add(e.Value.(*entry))
 	}
+	c.removeElement(e)
 }
 
 // removeElement is used to remove a given list element from the cache
 func (c *Cache) removeElement(e *list.Element) {
 	c.evictList.Remove(e)
 	kv := e.Value.(*entry)
-	delete(c.cache, kv.key)
+	delete(c.cache, kv.id)
+	c.leaveGroups(kv)
+	c.notifyAvailable(kv)
 	if c.OnEvicted != nil {
-		c.OnEvicted(kv.key, interface{}(e))
+		value := interface{}(kv.value)
+		if c.ValueTransformer != nil {
+			value = c.ValueTransformer(kv.key, kv.value)
+		}
+		if c.evictQueue != nil {
+			c.lock.hold(c.evictQueue, c.OnEvicted, kv.key, value)
+			return
+		}
+		c.OnEvicted(kv.key, value)
 	}
 }
@@ -89,6 +89,182 @@ func TestGet(t *testing.T) {
 
 }
 
+func TestLifetime(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(10, 2*time.Second)
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	maxCount := 5
+	for i := 0; i < 8; i++ {
+		_, _ = rl.Incr(key, maxCount)
+	}
+
+	// move past the rate period so the next increment over the limit resets the window
+	now = now.Add(3 * time.Second)
+	cnt, _ := rl.Incr(key, maxCount)
+	if cnt != 1 {
+		t.Fatalf("expected the windowed count to reset to [1] but got [%d]", cnt)
+	}
+
+	total, ok := rl.Lifetime(key)
+	if !ok {
+		t.Fatalf("expected foo to have a lifetime total")
+	}
+	if total != 9 {
+		t.Fatalf("expected a lifetime total of [9] but got [%d]", total)
+	}
+
+	_, _ = rl.Incr(key, maxCount)
+	cnt, _ = rl.Get(key)
+	total, _ = rl.Lifetime(key)
+	if cnt != 2 || total != 10 {
+		t.Fatalf("expected windowed count [2] and lifetime [10] but got [%d] and [%d]", cnt, total)
+	}
+
+	if _, ok := rl.Lifetime("bar"); ok {
+		t.Fatalf("expected no lifetime total for a key that was never incremented")
+	}
+}
+
+// keys first incremented in the same granular bucket should reset together
+func TestGranularity(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(12 * time.Second)
+
+	rl, _ := New(10, 10*time.Second, WithGranularity(10*time.Second))
+	rl.now = func() time.Time { return now }
+
+	maxCount := 1
+	_, _ = rl.Incr("foo", maxCount)
+	now = start.Add(17 * time.Second)
+	_, _ = rl.Incr("bar", maxCount)
+
+	// both windows started at 10s, so at 21s they're both past the rate period
+	now = start.Add(21 * time.Second)
+	for _, key := range []string{"foo", "bar"} {
+		cnt, underRateLimit := rl.Incr(key, maxCount)
+		if !underRateLimit || cnt != 1 {
+			t.Fatalf("expected %s to reset with its bucket, got count [%d]", key, cnt)
+		}
+	}
+}
+
+func TestStaggeredAlignment(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(30 * time.Second)
+
+	granularity := time.Minute
+	rl, _ := New(1000, time.Minute, WithGranularity(granularity), WithStaggeredAlignment())
+	rl.now = func() time.Time { return now }
+
+	// windows start within the granularity before now, on an offset of their own
+	offsets := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("foo_%d", i)
+		_, _ = rl.Incr(key, 10)
+		updated := rl.cache[key].Value.(*entry).updated
+		if updated.After(now) || now.Sub(updated) >= granularity {
+			t.Fatalf("expected %s to start within [%v] before now, started [%v]", key, granularity, updated)
+		}
+		offsets[updated.Sub(start)%granularity] = true
+	}
+	if len(offsets) < 90 {
+		t.Fatalf("expected the boundaries to be spread over the granularity, have [%d] distinct", len(offsets))
+	}
+
+	// and the same key lands on the same offset every time
+	first := rl.cache["foo_0"].Value.(*entry).updated
+	rl2, _ := New(10, time.Minute, WithGranularity(granularity), WithStaggeredAlignment())
+	now = now.Add(7 * time.Minute)
+	rl2.now = func() time.Time { return now }
+	_, _ = rl2.Incr("foo_0", 10)
+	if again := rl2.cache["foo_0"].Value.(*entry).updated; again.Sub(first)%granularity != 0 {
+		t.Fatalf("expected foo_0 to keep its offset, started [%v] then [%v]", first, again)
+	}
+}
+
+// a granularity finer than the clock shouldn't change when windows reset
+func TestFineGranularity(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(12 * time.Second)
+
+	rl, _ := New(10, 10*time.Second, WithGranularity(time.Nanosecond))
+	rl.now = func() time.Time { return now }
+
+	maxCount := 1
+	_, _ = rl.Incr("foo", maxCount)
+	now = start.Add(17 * time.Second)
+	_, _ = rl.Incr("bar", maxCount)
+
+	now = start.Add(23 * time.Second)
+	if _, underRateLimit := rl.Incr("foo", maxCount); !underRateLimit {
+		t.Fatalf("expected foo to reset after its own rate period")
+	}
+	if _, underRateLimit := rl.Incr("bar", maxCount); underRateLimit {
+		t.Fatalf("expected bar to still be within its own rate period")
+	}
+}
+
+func TestIncrFull(t *testing.T) {
+	now := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl, _ := New(10, 2*time.Second)
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	maxCount := 3
+	for i := 1; i <= 4; i++ {
+		windowed, lifetime, underLimit := rl.IncrFull(key, maxCount)
+		if windowed != uint64(i) || lifetime != uint64(i) {
+			t.Fatalf("expected windowed and lifetime of [%d] got [%d] and [%d]", i, windowed, lifetime)
+		}
+		if underLimit != (i <= maxCount) {
+			t.Fatalf("expected increment [%d] under the limit to be [%t]", i, i <= maxCount)
+		}
+	}
+
+	// past the rate period the windowed count resets but lifetime carries on in the same call
+	now = now.Add(3 * time.Second)
+	windowed, lifetime, underLimit := rl.IncrFull(key, maxCount)
+	if windowed != 1 || lifetime != 5 || !underLimit {
+		t.Fatalf("expected windowed [1] and lifetime [5] under the limit, got [%d] and [%d] [%t]", windowed, lifetime, underLimit)
+	}
+}
+
+func TestOldestN(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("foo_%d", i)
+		for j := 0; j <= i; j++ {
+			_, _ = rl.Incr(key, 10)
+		}
+	}
+	// foo_0 is used again so it's no longer the oldest
+	_, _ = rl.Get("foo_0")
+
+	oldest := rl.OldestN(3)
+	want := []KeyCount{{"foo_1", 2}, {"foo_2", 3}, {"foo_3", 4}}
+	if len(oldest) != len(want) {
+		t.Fatalf("expected [%d] oldest entries got [%d]", len(want), len(oldest))
+	}
+	for i := range want {
+		if oldest[i] != want[i] {
+			t.Fatalf("expected oldest entries %v got %v", want, oldest)
+		}
+	}
+
+	if all := rl.OldestN(100); len(all) != 5 || all[4].Key != "foo_0" {
+		t.Fatalf("expected at most the [5] cached entries ending with foo_0, got %v", all)
+	}
+
+	// looking didn't change recency, foo_1 is still evicted first
+	rl.MaxEntries = 5
+	_, _ = rl.Incr("bar", 10)
+	if rl.Contains("foo_1") {
+		t.Fatalf("expected OldestN not to mark entries as used")
+	}
+}
+
 func TestRemove(t *testing.T) {
 	maxItemsInCache := 10
 	rl, _ := New(maxItemsInCache, 10*time.Second)
@@ -227,6 +403,55 @@ func TestOnEvictedCallback(t *testing.T) {
 
 }
 
+func TestWouldEvict(t *testing.T) {
+	maxItemsInCache := 10
+
+	for _, tc := range []struct {
+		filled  int
+		newKeys int
+	}{
+		{0, 5}, {5, 5}, {5, 6}, {10, 1}, {10, 3}, {8, 25}, {3, 0},
+	} {
+		rl, _ := New(maxItemsInCache, 10*time.Second)
+		for i := 0; i < tc.filled; i++ {
+			_, _ = rl.Incr(fmt.Sprintf("old_%d", i), 10)
+		}
+
+		predicted := rl.WouldEvict(tc.newKeys)
+
+		evicted := 0
+		rl.OnEvicted = func(key interface{}, value interface{}) {
+			if key.(string)[:4] == "old_" {
+				evicted++
+			}
+		}
+		for i := 0; i < tc.newKeys; i++ {
+			_, _ = rl.Incr(fmt.Sprintf("new_%d", i), 10)
+		}
+
+		if predicted != evicted {
+			t.Fatalf("with [%d] filled and [%d] new keys expected [%d] evictions, predicted [%d]", tc.filled, tc.newKeys, evicted, predicted)
+		}
+	}
+}
+
+// incrementing a key that's already cached shouldn't push anything out of a full cache
+func TestIncrExistingKeyDoesntEvict(t *testing.T) {
+	maxItemsInCache := 3
+	rl, _ := New(maxItemsInCache, 10*time.Second)
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		t.Fatalf("expected no evictions, but [%v] was evicted", key)
+	}
+
+	for i := 0; i < maxItemsInCache; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+	cnt, _ := rl.Incr("foo_0", 10)
+	if cnt != 2 {
+		t.Fatalf("expected foo_0 to have a count of [2] but got [%d]", cnt)
+	}
+}
+
 // BENCHMARKS
 // go test -bench=. -run=XXX
 // on macbook pro ~2.7 million ops a second
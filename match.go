@@ -0,0 +1,52 @@
+package ratelimiter
+
+// MatchCounts returns the count for every string key matching the glob pattern, where `*` matches
+// any run of characters and `?` matches a single character, e.g. "user:*:login".
+// Keys that aren't strings are skipped.
+func (c *Cache) MatchCounts(pattern string) map[string]uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	counts := make(map[string]uint64)
+	if c.closed {
+		return counts
+	}
+	// the map is keyed by hash when keys are hashed, the entries have the original key
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		if s, ok := e.key.(string); ok && globMatch(pattern, s) {
+			counts[s] = e.value
+		}
+	}
+	return counts
+}
+
+// globMatch reports whether s matches pattern, it backtracks to the most recent `*`
+// on a mismatch so it runs in O(len(pattern)*len(s)) at worst
+func globMatch(pattern, s string) bool {
+	pr, sr := []rune(pattern), []rune(s)
+	pi, si := 0, 0
+	star, mark := -1, 0
+
+	for si < len(sr) {
+		switch {
+		case pi < len(pr) && (pr[pi] == '?' || pr[pi] == sr[si]):
+			pi++
+			si++
+		case pi < len(pr) && pr[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case star >= 0:
+			// let the last star swallow one more character and try again
+			mark++
+			pi, si = star+1, mark
+		default:
+			return false
+		}
+	}
+
+	for pi < len(pr) && pr[pi] == '*' {
+		pi++
+	}
+	return pi == len(pr)
+}
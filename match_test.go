@@ -0,0 +1,51 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchCounts(t *testing.T) {
+	rl, _ := New(20, time.Hour)
+
+	counts := map[interface{}]int{
+		"user:1:login":  3,
+		"user:22:login": 1,
+		"user:3:logout": 2,
+		"admin:1:login": 4,
+		"user:4:":       1,
+		42:              5,
+	}
+	for key, cnt := range counts {
+		for i := 0; i < cnt; i++ {
+			_, _ = rl.Incr(key, 100)
+		}
+	}
+
+	for _, tc := range []struct {
+		pattern string
+		want    map[string]uint64
+	}{
+		{"user:*:login", map[string]uint64{"user:1:login": 3, "user:22:login": 1}},
+		{"user:?:login", map[string]uint64{"user:1:login": 3}},
+		{"*:1:*", map[string]uint64{"user:1:login": 3, "admin:1:login": 4}},
+		{"user:*:log*", map[string]uint64{"user:1:login": 3, "user:22:login": 1, "user:3:logout": 2}},
+		{"user:4:*", map[string]uint64{"user:4:": 1}},
+		{"admin:1:login", map[string]uint64{"admin:1:login": 4}},
+		{"nobody:*", map[string]uint64{}},
+	} {
+		got := rl.MatchCounts(tc.pattern)
+		if len(got) != len(tc.want) {
+			t.Fatalf("expected [%d] matches for [%s] but got %v", len(tc.want), tc.pattern, got)
+		}
+		for key, cnt := range tc.want {
+			if got[key] != cnt {
+				t.Fatalf("expected %s to match [%s] with a count of [%d] but got [%d]", key, tc.pattern, cnt, got[key])
+			}
+		}
+	}
+
+	if got := rl.MatchCounts("*"); len(got) != 5 {
+		t.Fatalf("expected * to match every string key but not 42, got [%d] matches", len(got))
+	}
+}
@@ -0,0 +1,85 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"time"
+	"unsafe"
+)
+
+const (
+	// entryOverhead approximates what each entry costs besides the key itself,
+	// the entry and list element plus a map slot holding an interface key and element pointer
+	entryOverhead = int64(unsafe.Sizeof(entry{})) + int64(unsafe.Sizeof(list.Element{})) + 48
+
+	// minExpiryDivisor caps how far a memory budget can shorten expiry, to ratePeriod/minExpiryDivisor
+	minExpiryDivisor = 16
+)
+
+// ApproxMemoryBytes estimates how much memory the cache entries use. It counts a fixed overhead per
+// entry plus the length of string keys, other key types are assumed to fit in the overhead.
+func (c *Cache) ApproxMemoryBytes() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.approxMemoryBytes()
+}
+
+// approxMemoryBytes is ApproxMemoryBytes for callers that already hold the lock
+func (c *Cache) approxMemoryBytes() int64 {
+	total := entryOverhead * int64(c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		if k, ok := e.key.(string); ok {
+			total += int64(len(k))
+		}
+		total += int64(len(e.history)) * int64(unsafe.Sizeof(historyBucket{}))
+	}
+	return total
+}
+
+// WithMemoryBudget has the janitor keep the cache under roughly bytes of memory, as estimated by
+// ApproxMemoryBytes, on top of MaxEntries. Whenever a sweep finds the cache over budget it halves how
+// long an idle entry is kept before it's swept, down to ratePeriod/16, and once memory is comfortably
+// under budget again it doubles it back up to ratePeriod. Only the janitor acts on the shortened expiry,
+// so this needs StartJanitor, and a key swept early simply starts over with a fresh count.
+func WithMemoryBudget(bytes int64) Option {
+	return func(c *Cache) {
+		c.memoryBudget = bytes
+	}
+}
+
+// EffectiveExpiry returns how long the janitor currently keeps an idle entry, this is the ratePeriod
+// unless a memory budget has shortened it.
+func (c *Cache) EffectiveExpiry() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.expiry > 0 {
+		return c.expiry
+	}
+	return c.ratePeriod
+}
+
+// tuneExpiry shortens the janitor expiry while over the memory budget and relaxes it when under
+func (c *Cache) tuneExpiry() {
+	if c.ratePeriod <= 0 {
+		return
+	}
+	if c.expiry <= 0 {
+		c.expiry = c.ratePeriod
+	}
+
+	used := c.approxMemoryBytes()
+	switch {
+	case used > c.memoryBudget:
+		c.expiry /= 2
+		if min := c.ratePeriod / minExpiryDivisor; c.expiry < min {
+			c.expiry = min
+		}
+	case used < c.memoryBudget*3/4:
+		c.expiry *= 2
+		if c.expiry > c.ratePeriod {
+			c.expiry = c.ratePeriod
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApproxMemoryBytes(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	if used := rl.ApproxMemoryBytes(); used != 0 {
+		t.Fatalf("expected an empty cache to use [0] bytes, got [%d]", used)
+	}
+
+	_, _ = rl.Incr("foo", 10)
+	small := rl.ApproxMemoryBytes()
+	_, _ = rl.Incr(strings.Repeat("x", 1000), 10)
+	if used := rl.ApproxMemoryBytes(); used < 2*small+997 {
+		t.Fatalf("expected a large key to count its length, got [%d] bytes", used)
+	}
+}
+
+func TestMemoryBudgetShortensExpiry(t *testing.T) {
+	clock := newFakeClock()
+	ratePeriod := 16 * time.Second
+	rl, _ := New(100, ratePeriod, WithMemoryBudget(10*entryOverhead))
+	rl.now = clock.Now
+
+	// 20 keys at 0s and 20 more at 5s puts us well over a 10 entry budget
+	for i := 0; i < 20; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("old_%d", i), 10)
+	}
+	clock.Add(5 * time.Second)
+	for i := 0; i < 20; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("new_%d", i), 10)
+	}
+
+	// over budget halves expiry to 8s, nothing's been idle that long yet
+	clock.Add(time.Second)
+	if removed := rl.sweep(); removed != 0 {
+		t.Fatalf("expected nothing idle for more than 8s, removed [%d]", removed)
+	}
+	if expiry := rl.EffectiveExpiry(); expiry != 8*time.Second {
+		t.Fatalf("expected expiry to shorten to [8s] got [%s]", expiry)
+	}
+
+	// still over budget so expiry drops to 4s and the old keys go, well before the 16s rate period
+	if removed := rl.sweep(); removed != 20 {
+		t.Fatalf("expected the [20] old keys to expire early, removed [%d]", removed)
+	}
+	if _, ok := rl.Get("new_0"); !ok {
+		t.Fatalf("expected keys idle for less than the shortened expiry to stay")
+	}
+
+	// the new keys are still over budget, so expiry keeps shortening until they go too
+	clock.Add(3 * time.Second)
+	_ = rl.sweep()
+	if rl.Len() != 0 {
+		t.Fatalf("expected the remaining keys to be swept under pressure, have [%d]", rl.Len())
+	}
+
+	// with memory under budget expiry relaxes back up
+	for i := 0; i < 5; i++ {
+		_ = rl.sweep()
+	}
+	if expiry := rl.EffectiveExpiry(); expiry != ratePeriod {
+		t.Fatalf("expected expiry to relax back to [%s] got [%s]", ratePeriod, expiry)
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimiter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// metricsTopKeys is how many of the highest count keys are included in Metrics
+const metricsTopKeys = 10
+
+// Metrics is a point in time summary of the cache, it's what StartMetricsExport writes as JSON
+type Metrics struct {
+	Time       time.Time    `json:"time"`
+	Size       int          `json:"size"`
+	Capacity   int          `json:"capacity"`
+	Evictions  uint64       `json:"evictions"`
+	Violations uint64       `json:"violations"`
+	TopKeys    []MetricsKey `json:"top_keys"`
+}
+
+// MetricsKey is one of the highest count keys in Metrics, the key is formatted with fmt
+// so it can be written as JSON whatever its type
+type MetricsKey struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+}
+
+// Metrics returns a summary of the cache size, the number of evictions and rate limit violations
+// since it was created, and the keys with the highest counts.
+func (c *Cache) Metrics() Metrics {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	m := Metrics{
+		Time:       c.now(),
+		Size:       c.evictList.Len(),
+		Capacity:   c.MaxEntries,
+		Evictions:  c.counters.evictions,
+		Violations: c.counters.violations,
+		TopKeys:    make([]MetricsKey, 0, c.evictList.Len()),
+	}
+	if c.closed {
+		m.Size = 0
+		return m
+	}
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		m.TopKeys = append(m.TopKeys, MetricsKey{fmt.Sprint(e.key), e.value})
+	}
+	sortMetricsKeys(m.TopKeys)
+	if len(m.TopKeys) > metricsTopKeys {
+		m.TopKeys = m.TopKeys[:metricsTopKeys]
+	}
+	return m
+}
+
+// sortMetricsKeys orders keys from the highest count down, keeping recency order for ties
+func sortMetricsKeys(keys []MetricsKey) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Count > keys[j].Count
+	})
+}
+
+// StartMetricsExport starts a background goroutine that writes Metrics to w as a line of JSON
+// every interval, for collectors that tail a file or read from a pipe. Write errors are ignored.
+// It does nothing if an export is already running.
+func (c *Cache) StartMetricsExport(interval time.Duration, w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || c.metricsStop != nil || interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	c.metricsStop = stop
+	go func() {
+		enc := json.NewEncoder(w)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = enc.Encode(c.Metrics())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopMetricsExport stops the background goroutine started by StartMetricsExport, it's safe
+// to call when no export is running.
+func (c *Cache) StopMetricsExport() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopMetricsExport()
+}
+
+// stopMetricsExport is StopMetricsExport for callers that already hold the write lock
+func (c *Cache) stopMetricsExport() {
+	if c.metricsStop != nil {
+		close(c.metricsStop)
+		c.metricsStop = nil
+	}
+}
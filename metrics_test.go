@@ -0,0 +1,86 @@
+package ratelimiter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer that's safe to write from a background goroutine
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func TestMetricsExport(t *testing.T) {
+	rl, _ := New(2, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		_, _ = rl.Incr("foo", 3)
+	}
+	_, _ = rl.Incr("bar", 3)
+	_, _ = rl.Incr("baz", 3)
+
+	var out lockedBuffer
+	rl.StartMetricsExport(10*time.Millisecond, &out)
+	deadline := time.Now().Add(2 * time.Second)
+	for bytes.Count(out.Bytes(), []byte("\n")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	rl.StopMetricsExport()
+
+	scanner := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		var m Metrics
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("expected each line to be valid JSON, got %v for %q", err, scanner.Text())
+		}
+		if m.Size != 2 || m.Capacity != 2 {
+			t.Fatalf("expected size [2] and capacity [2], got [%d] and [%d]", m.Size, m.Capacity)
+		}
+		if m.Evictions != 1 {
+			t.Fatalf("expected [1] eviction, got [%d]", m.Evictions)
+		}
+		if m.Violations != 2 {
+			t.Fatalf("expected [2] violations, got [%d]", m.Violations)
+		}
+		if len(m.TopKeys) != 2 {
+			t.Fatalf("expected [2] top keys, got [%d]", len(m.TopKeys))
+		}
+		if m.Time.IsZero() {
+			t.Fatalf("expected the metrics to be timestamped")
+		}
+	}
+	if lines < 2 {
+		t.Fatalf("expected at least [2] metrics lines, got [%d]", lines)
+	}
+
+	// the field names are what collectors key on, so check them on the wire
+	raw := map[string]interface{}{}
+	firstLine := bytes.SplitN(out.Bytes(), []byte("\n"), 2)[0]
+	if err := json.Unmarshal(firstLine, &raw); err != nil {
+		t.Fatalf("unable to decode metrics: %v", err)
+	}
+	for _, field := range []string{"time", "size", "capacity", "evictions", "violations", "top_keys"} {
+		if _, ok := raw[field]; !ok {
+			t.Fatalf("expected the metrics JSON to have a [%s] field", field)
+		}
+	}
+}
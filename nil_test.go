@@ -0,0 +1,83 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNilKey(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+
+	if cnt, ok := rl.Incr(nil, 10); ok || cnt != 0 {
+		t.Fatalf("expected Incr with a nil key to be rejected, got count [%d]", cnt)
+	}
+	if cnt, lifetime, ok := rl.IncrFull(nil, 10); ok || cnt != 0 || lifetime != 0 {
+		t.Fatalf("expected IncrFull with a nil key to be rejected")
+	}
+	if _, ok := rl.IncrInGroup(nil, "group", 10); ok {
+		t.Fatalf("expected IncrInGroup with a nil key to be rejected")
+	}
+	if _, ok := rl.IncrKind(nil, Read, 10); ok {
+		t.Fatalf("expected IncrKind with a nil key to be rejected")
+	}
+	if _, ok := rl.IncrBanked(nil, 10, 10); ok {
+		t.Fatalf("expected IncrBanked with a nil key to be rejected")
+	}
+	if rl.AllowEWMA(nil, 10) {
+		t.Fatalf("expected AllowEWMA with a nil key to be rejected")
+	}
+	if rl.AllowAtTime(nil, 10, time.Now()) {
+		t.Fatalf("expected AllowAtTime with a nil key to be rejected")
+	}
+	if rl.IncrDistinctChild(nil, "child", 10) {
+		t.Fatalf("expected IncrDistinctChild with a nil parent to be rejected")
+	}
+	if release, ok := rl.Acquire(nil, 10); ok || release != nil {
+		t.Fatalf("expected Acquire with a nil key to be rejected")
+	}
+	if _, existed := rl.Swap(nil, 5); existed {
+		t.Fatalf("expected Swap with a nil key to be rejected")
+	}
+	rl.SetLimit(nil, 5)
+	rl.SetResetHandler(nil, func(uint64) {})
+	rl.Protect(nil)
+
+	if _, ok := rl.Get(nil); ok {
+		t.Fatalf("expected Get with a nil key to report it missing")
+	}
+	if _, ok := rl.Peek(nil); ok {
+		t.Fatalf("expected Peek with a nil key to report it missing")
+	}
+	if _, ok := rl.Lifetime(nil); ok {
+		t.Fatalf("expected Lifetime with a nil key to report it missing")
+	}
+	if rl.Contains(nil) {
+		t.Fatalf("expected Contains with a nil key to report it missing")
+	}
+	rl.Remove(nil)
+
+	if rl.Len() != 1 || !rl.Contains("foo") {
+		t.Fatalf("expected nil keys to leave the cache alone, have [%d] keys", rl.Len())
+	}
+
+	// nothing nil made it in, so a snapshot still round trips
+	var buf bytes.Buffer
+	if err := rl.Save(&buf); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+}
+
+// a typed nil isn't a nil interface, so it's an ordinary key
+func TestTypedNilKey(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	var key *int
+	if _, ok := rl.Incr(key, 10); !ok {
+		t.Fatalf("expected a typed nil key to be allowed")
+	}
+	if !rl.Contains(key) {
+		t.Fatalf("expected a typed nil key to be stored")
+	}
+}
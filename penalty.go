@@ -0,0 +1,84 @@
+package ratelimiter
+
+import (
+	"math"
+	"time"
+)
+
+// penaltyBox is the configuration for WithPenaltyBox
+type penaltyBox struct {
+	base   time.Duration
+	factor float64
+	max    time.Duration
+	quiet  time.Duration
+}
+
+// WithPenaltyBox blocks a key outright whenever Incr puts it over its rate limit, for base the first
+// time, then base times factor, then times factor again and so on up to max, so repeat offenders are
+// kept out for longer each time. Every Incr while a key is blocked is denied whatever its count. Once
+// a key has gone quiet, with no violation for the quiet duration after its last block ended, it starts
+// over at base. A max of zero or less leaves the blocks uncapped. The penalty lives with the entry so
+// it's dropped if the key is evicted or removed.
+func WithPenaltyBox(base time.Duration, factor float64, max, quiet time.Duration) Option {
+	return func(c *Cache) {
+		if factor < 1 {
+			factor = 1
+		}
+		c.penalty = &penaltyBox{base: base, factor: factor, max: max, quiet: quiet}
+	}
+}
+
+// penalize reports whether the entry is blocked by the penalty box, putting it in for the next
+// level up if it just violated its rate limit
+func (c *Cache) penalize(e *entry, violated bool) bool {
+	now := c.now()
+	if now.Before(e.penaltyUntil) {
+		return true
+	}
+	if !violated {
+		return false
+	}
+
+	e.penaltyLevel = c.penaltyLevel(e, now)
+	max := c.penalty.max
+	if max <= 0 {
+		max = math.MaxInt64
+	}
+	d := float64(c.penalty.base)
+	for i := 0; i < e.penaltyLevel && d < float64(max); i++ {
+		d *= c.penalty.factor
+	}
+	block := max
+	if d < float64(max) {
+		block = time.Duration(d)
+	}
+	e.penaltyUntil = now.Add(block)
+	e.penaltyLevel++
+	return true
+}
+
+// penaltyLevel returns the entry's penalty level, which starts over once it's been quiet long enough
+func (c *Cache) penaltyLevel(e *entry, now time.Time) int {
+	if e.penaltyLevel > 0 && now.Sub(e.penaltyUntil) >= c.penalty.quiet {
+		return 0
+	}
+	return e.penaltyLevel
+}
+
+// PenaltyLevel returns how many times in a row key has been put in the penalty box, zero if it
+// hasn't or has since gone quiet, and when its current block ends, which is in the past if it isn't
+// blocked right now. ok is false if the key isn't cached or the cache has no penalty box.
+func (c *Cache) PenaltyLevel(key interface{}) (level int, blockedUntil time.Time, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.penalty == nil || c.closed {
+		return 0, time.Time{}, false
+	}
+	ent, ok := c.find(key)
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	e := ent.Value.(*entry)
+	return c.penaltyLevel(e, c.now()), e.penaltyUntil, true
+}
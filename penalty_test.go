@@ -0,0 +1,75 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPenaltyBox(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Second, WithPenaltyBox(10*time.Second, 2, 30*time.Second, time.Minute))
+	rl.now = clock.Now
+
+	// going over the limit again as soon as each block ends escalates the next one up to the cap
+	for _, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second} {
+		rl.Incr("key", 1)
+		if _, ok := rl.Incr("key", 1); ok {
+			t.Fatalf("expected the second increment to be a violation")
+		}
+		level, until, _ := rl.PenaltyLevel("key")
+		if until.Sub(clock.Now()) != expected {
+			t.Fatalf("expected a [%v] block at level [%d], got [%v]", expected, level, until.Sub(clock.Now()))
+		}
+
+		// the period lifts long before the block does
+		clock.Add(expected - time.Second)
+		if _, ok := rl.Incr("key", 1); ok {
+			t.Fatalf("expected the key to stay blocked for [%v]", expected)
+		}
+		clock.Add(2 * time.Second)
+	}
+	if level, _, _ := rl.PenaltyLevel("key"); level != 4 {
+		t.Fatalf("expected level [4] after 4 blocks, got [%d]", level)
+	}
+
+	// allowed once out of the box, and back at the start after staying quiet
+	if _, ok := rl.Incr("key", 1); !ok {
+		t.Fatalf("expected the key to be let out of the penalty box")
+	}
+	clock.Add(time.Minute)
+	if level, _, _ := rl.PenaltyLevel("key"); level != 0 {
+		t.Fatalf("expected the level to reset after a quiet period, got [%d]", level)
+	}
+	rl.Incr("key", 1)
+	rl.Incr("key", 1)
+	if level, until, _ := rl.PenaltyLevel("key"); level != 1 || until.Sub(clock.Now()) != 10*time.Second {
+		t.Fatalf("expected the next violation to start over at the base, got [%d] for [%v]", level, until.Sub(clock.Now()))
+	}
+}
+
+func TestPenaltyLevelWithoutPenaltyBox(t *testing.T) {
+	rl, _ := New(10, time.Second)
+	rl.Incr("key", 1)
+	if _, _, ok := rl.PenaltyLevel("key"); ok {
+		t.Fatalf("expected no penalty level without a penalty box")
+	}
+}
+
+func TestPenaltyBoxUncapped(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Second, WithPenaltyBox(10*time.Second, 2, 0, time.Minute))
+	rl.now = clock.Now
+
+	// without a max the blocks keep doubling
+	for _, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second} {
+		rl.Incr("key", 1)
+		if _, ok := rl.Incr("key", 1); ok {
+			t.Fatalf("expected the second increment to be a violation")
+		}
+		level, until, _ := rl.PenaltyLevel("key")
+		if until.Sub(clock.Now()) != expected {
+			t.Fatalf("expected a [%v] block at level [%d], got [%v]", expected, level, until.Sub(clock.Now()))
+		}
+		clock.Add(expected + time.Second)
+	}
+}
@@ -0,0 +1,109 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is written as the first byte of every Save, bump it whenever
+// the layout of snapshot changes so Load can refuse data it doesn't understand
+const snapshotVersion byte = 1
+
+// snapshot is what Save writes after the version byte
+type snapshot struct {
+	MaxEntries int
+	RatePeriod time.Duration
+	Entries    []snapshotEntry
+}
+
+type snapshotEntry struct {
+	Key     interface{}
+	Value   uint64
+	Total   uint64
+	Updated time.Time
+}
+
+// Save writes the cache capacity, rate period and every entry to w so it can be restored
+// with Load. Keys are gob encoded, so any key type other than the basic types must be
+// registered with gob.Register first.
+func (c *Cache) Save(w io.Writer) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	snap := snapshot{
+		MaxEntries: c.MaxEntries,
+		RatePeriod: c.ratePeriod,
+		Entries:    make([]snapshotEntry, 0, c.evictList.Len()),
+	}
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		snap.Entries = append(snap.Entries, snapshotEntry{e.key, e.value, e.total, e.updated})
+	}
+
+	if _, err := w.Write([]byte{snapshotVersion}); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(&snap)
+}
+
+// Load replaces the cache capacity, rate period and entries with ones previously written
+// by Save, keeping their recency order. Nil keys are skipped. Nothing is changed if the data can't
+// be read.
+func (c *Cache) Load(r io.Reader) error {
+	version := make([]byte, 1)
+	if _, err := io.ReadFull(r, version); err != nil {
+		return fmt.Errorf("Unable to read snapshot version: %v", err)
+	}
+	if version[0] != snapshotVersion {
+		return fmt.Errorf("Unsupported snapshot version [%d], expected [%d]", version[0], snapshotVersion)
+	}
+
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("Unable to decode snapshot: %v", err)
+	}
+	if snap.MaxEntries <= 0 {
+		return fmt.Errorf("Snapshot has an invalid size [%d]", snap.MaxEntries)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	c.MaxEntries = snap.MaxEntries
+	c.ratePeriod = snap.RatePeriod
+	c.dropEntries()
+	c.evictList = list.New()
+	c.cache = make(map[interface{}]*list.Element, len(snap.Entries))
+	for _, se := range snap.Entries {
+		if c.evictList.Len() >= c.MaxEntries {
+			break
+		}
+		if se.Key == nil {
+			continue
+		}
+		item := &entry{id: c.storedID(se.Key), key: se.Key, value: se.Value, total: se.Total, updated: se.Updated, started: se.Updated}
+		c.cache[item.id] = c.evictList.PushBack(item)
+	}
+	return nil
+}
+
+// dropEntries lets go of every entry ahead of the list and map being replaced wholesale, taking them
+// out of their groups and waking AvailableAfter waiters the same as restore does
+func (c *Cache) dropEntries() {
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		c.leaveGroups(e)
+		c.notifyAvailable(e)
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimiter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	rl, _ := New(10, 5*time.Second)
+
+	counts := map[string]int{"foo": 3, "bar": 1, "baz": 7}
+	for _, key := range []string{"foo", "bar", "baz"} {
+		for i := 0; i < counts[key]; i++ {
+			_, _ = rl.Incr(key, 100)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := rl.Save(&buf); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+
+	loaded, _ := New(1, 0)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("unable to load cache: %v", err)
+	}
+
+	if loaded.MaxEntries != 10 {
+		t.Fatalf("expected loaded capacity of [10] but got [%d]", loaded.MaxEntries)
+	}
+	if loaded.ratePeriod != 5*time.Second {
+		t.Fatalf("expected loaded rate period of [5s] but got [%s]", loaded.ratePeriod)
+	}
+
+	// recency survives the round trip, foo was incremented first so it's the oldest
+	if oldest := loaded.evictList.Back().Value.(*entry).key; oldest != "foo" {
+		t.Fatalf("expected foo to be the oldest key after a load, got [%v]", oldest)
+	}
+
+	for key, want := range counts {
+		cnt, ok := loaded.Get(key)
+		if !ok || cnt != uint64(want) {
+			t.Fatalf("expected %s to load with a count of [%d] but got [%d]", key, want, cnt)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	rl, _ := New(10, 5*time.Second)
+	_, _ = rl.Incr("foo", 100)
+
+	var buf bytes.Buffer
+	if err := rl.Save(&buf); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+	data := buf.Bytes()
+	data[0] = 99
+
+	err := rl.Load(bytes.NewReader(data))
+	if err == nil {
+		t.Fatalf("expected loading an unknown version to fail")
+	}
+	if !strings.Contains(err.Error(), "version [99]") {
+		t.Fatalf("expected the error to name the bad version, got [%v]", err)
+	}
+
+	// a failed load leaves the cache alone
+	if cnt, _ := rl.Get("foo"); cnt != 1 {
+		t.Fatalf("expected foo to keep a count of [1] after a failed load but got [%d]", cnt)
+	}
+}
+
+// loading over a cache lets go of the entries it replaces
+func TestLoadDropsOldEntries(t *testing.T) {
+	src, _ := New(10, time.Hour)
+	src.Incr("foo", 10)
+	var snap bytes.Buffer
+	if err := src.Save(&snap); err != nil {
+		t.Fatalf("unable to save cache: %v", err)
+	}
+
+	rl, _ := New(10, time.Hour)
+	rl.IncrInGroup("foo", "group", 1)
+	rl.IncrInGroup("foo", "group", 1)
+	available := rl.AvailableAfter("foo")
+	if err := rl.Load(&snap); err != nil {
+		t.Fatalf("unable to load cache: %v", err)
+	}
+
+	select {
+	case <-available:
+	default:
+		t.Fatalf("expected AvailableAfter waiters on the replaced entry to be woken")
+	}
+	if removed := rl.RemoveGroup("group"); removed != 0 || !rl.Contains("foo") {
+		t.Fatalf("expected the loaded key to have left the old entry's group, removed [%d]", removed)
+	}
+}
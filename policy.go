@@ -0,0 +1,21 @@
+package ratelimiter
+
+// EvictionPolicy decides which entry a full cache evicts first
+type EvictionPolicy int
+
+const (
+	// LRU is the default, the least recently used entry is evicted first and
+	// every Incr or Get on a key marks it as used
+	LRU EvictionPolicy = iota
+
+	// FIFO evicts the earliest inserted entry first, however often it's been used since
+	FIFO
+)
+
+// WithEvictionPolicy sets which entry a full cache evicts first. Under FIFO, Get, Incr and everything
+// else that would mark a key as recently used leave the eviction order alone, so it's purely insertion order.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(c *Cache) {
+		c.policy = policy
+	}
+}
@@ -0,0 +1,47 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// fillAndChurn adds foo, bar and baz to a cache of three, uses foo heavily and then adds one more key
+func fillAndChurn(rl *Cache) {
+	for _, key := range []string{"foo", "bar", "baz"} {
+		_, _ = rl.Incr(key, 100)
+	}
+	for i := 0; i < 10; i++ {
+		_, _ = rl.Incr("foo", 100)
+		_, _ = rl.Get("foo")
+	}
+	_, _ = rl.Incr("qux", 100)
+}
+
+func TestFIFOEviction(t *testing.T) {
+	rl, _ := New(3, time.Hour, WithEvictionPolicy(FIFO))
+	fillAndChurn(rl)
+
+	if rl.Contains("foo") {
+		t.Fatalf("expected frequent access not to protect foo from FIFO eviction")
+	}
+	for _, key := range []string{"bar", "baz", "qux"} {
+		if !rl.Contains(key) {
+			t.Fatalf("expected %s to survive FIFO eviction", key)
+		}
+	}
+	if keys := rl.Keys(); keys[0] != "bar" || keys[2] != "qux" {
+		t.Fatalf("expected keys to stay in insertion order, got %v", keys)
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	rl, _ := New(3, time.Hour, WithEvictionPolicy(LRU))
+	fillAndChurn(rl)
+
+	if !rl.Contains("foo") {
+		t.Fatalf("expected frequent access to keep foo cached under LRU")
+	}
+	if rl.Contains("bar") {
+		t.Fatalf("expected the least recently used key bar to be evicted")
+	}
+}
@@ -0,0 +1,32 @@
+package ratelimiter
+
+// WouldAllow reports whether Incr(key, maxValue) would be under the rate limit if it were called
+// now, without counting anything, moving the key in the LRU, or firing any callbacks. It follows the
+// same decision Incr makes, including per key limits, warmup, an expired window, ZeroPeriodMode and
+// the penalty box.
+func (c *Cache) WouldAllow(key interface{}, maxValue int) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if key == nil || c.closed {
+		return false
+	}
+
+	ee, ok := c.find(key)
+	if !ok {
+		// a new key always starts at 1 and is let through, if there's room for it
+		return !c.full(key)
+	}
+
+	e := ee.Value.(*entry)
+	if c.penalty != nil && c.now().Before(e.penaltyUntil) {
+		return false
+	}
+	if e.value+e.remoteTotal+1 <= uint64(c.effectiveLimit(e, maxValue)) {
+		return true
+	}
+	if c.ratePeriod > 0 {
+		return c.expired(e, c.now())
+	}
+	return c.zeroPeriodMode != ZeroPeriodBlock
+}
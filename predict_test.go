@@ -0,0 +1,82 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWouldAllowAgreesWithIncr(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	check := func(state string) {
+		before, _ := rl.Peek("key")
+		predicted := rl.WouldAllow("key", 3)
+		if after, _ := rl.Peek("key"); after != before {
+			t.Fatalf("expected WouldAllow to leave the count alone when %s", state)
+		}
+		if _, allowed := rl.Incr("key", 3); allowed != predicted {
+			t.Fatalf("expected WouldAllow [%t] to match Incr [%t] when %s", predicted, allowed, state)
+		}
+	}
+
+	check("fresh")
+	check("under the limit")
+	check("near the limit")
+	check("over the limit")
+	check("still over the limit")
+
+	clock.Add(11 * time.Second)
+	check("just expired")
+	check("in the new window")
+}
+
+func TestWouldAllowPolicies(t *testing.T) {
+	rl, _ := New(10, 0, WithZeroPeriodMode(ZeroPeriodCount))
+	rl.Incr("key", 1)
+	if !rl.WouldAllow("key", 1) {
+		t.Fatalf("expected ZeroPeriodCount to allow past the limit")
+	}
+
+	rl, _ = New(10, time.Hour)
+	rl.SetLimit("key", 5)
+	rl.Incr("key", 1)
+	if !rl.WouldAllow("key", 1) {
+		t.Fatalf("expected the key's own limit to take precedence over maxValue")
+	}
+
+	if rl.WouldAllow(nil, 1) {
+		t.Fatalf("expected a nil key to be refused")
+	}
+	rl.Close()
+	if rl.WouldAllow("key", 5) {
+		t.Fatalf("expected a closed cache to refuse")
+	}
+}
+
+func TestWouldAllowPenaltyBox(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Second, WithPenaltyBox(10*time.Second, 2, time.Minute, time.Minute))
+	rl.now = clock.Now
+
+	rl.Incr("key", 1)
+	rl.Incr("key", 1)
+
+	// the period has lifted but the key is still in the box
+	clock.Add(2 * time.Second)
+	if rl.WouldAllow("key", 1) {
+		t.Fatalf("expected a key in the penalty box to be refused")
+	}
+	if _, ok := rl.Incr("key", 1); ok {
+		t.Fatalf("expected Incr to agree the key is blocked")
+	}
+
+	clock.Add(10 * time.Second)
+	if !rl.WouldAllow("key", 1) {
+		t.Fatalf("expected the key to be allowed once its block ends")
+	}
+	if _, ok := rl.Incr("key", 1); !ok {
+		t.Fatalf("expected Incr to agree the key is let out")
+	}
+}
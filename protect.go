@@ -0,0 +1,35 @@
+package ratelimiter
+
+// Protect keeps key from being evicted when the cache is full, the next oldest unprotected
+// entry is evicted instead. Protection lasts until Unprotect, even across the key being removed
+// and added again. If every entry in a full cache is protected the oldest is still evicted,
+// so protect sparingly. Protecting a key doesn't stop Remove or RemoveGroup from removing it.
+func (c *Cache) Protect(key interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return
+	}
+	if c.protected == nil {
+		c.protected = make(map[interface{}]struct{})
+	}
+	c.protected[c.id(key)] = struct{}{}
+}
+
+// Unprotect lets key be evicted for capacity again.
+func (c *Cache) Unprotect(key interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	delete(c.protected, c.id(key))
+}
+
+// isProtected reports whether the entry stored under id is exempt from capacity eviction
+func (c *Cache) isProtected(id interface{}) bool {
+	_, ok := c.protected[id]
+	return ok
+}
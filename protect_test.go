@@ -0,0 +1,59 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestProtectSurvivesEviction(t *testing.T) {
+	rl, _ := New(3, 10*time.Second)
+
+	rl.Protect("vip")
+	_, _ = rl.Incr("vip", 10)
+	for i := 0; i < 10; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+
+	if _, ok := rl.Get("vip"); !ok {
+		t.Fatalf("expected protected key to survive eviction pressure")
+	}
+	if rl.Len() != 3 {
+		t.Fatalf("expected the cache to stay at [3] entries, have [%d]", rl.Len())
+	}
+	if _, ok := rl.Get("foo_0"); ok {
+		t.Fatalf("expected unprotected foo_0 to be evicted")
+	}
+
+	rl.Unprotect("vip")
+	for i := 10; i < 13; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+	if _, ok := rl.Get("vip"); ok {
+		t.Fatalf("expected vip to be evictable again after Unprotect")
+	}
+}
+
+// when everything is protected we still have to make room
+func TestProtectAllFallsBackToOldest(t *testing.T) {
+	rl, _ := New(2, 10*time.Second)
+
+	for _, key := range []string{"foo", "bar", "baz"} {
+		rl.Protect(key)
+	}
+
+	var evicted []interface{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		evicted = append(evicted, key)
+	}
+	for _, key := range []string{"foo", "bar", "baz"} {
+		_, _ = rl.Incr(key, 10)
+	}
+
+	if rl.Len() != 2 {
+		t.Fatalf("expected the cache to stay at [2] entries, have [%d]", rl.Len())
+	}
+	if len(evicted) != 1 || evicted[0] != "foo" {
+		t.Fatalf("expected the oldest key foo to be evicted, got %v", evicted)
+	}
+}
@@ -0,0 +1,28 @@
+package ratelimiter
+
+import "time"
+
+// Range calls fn for every entry from oldest to newest, stopping as soon as fn returns false, with when
+// the entry's current rate period began. The entries are copied under the lock first, so fn is free to
+// call back into the cache and sees the cache as it was when Range was called.
+func (c *Cache) Range(fn func(key interface{}, value uint64, updated time.Time) bool) {
+	type ranged struct {
+		key     interface{}
+		value   uint64
+		updated time.Time
+	}
+
+	c.lock.RLock()
+	entries := make([]ranged, 0, c.evictList.Len())
+	for ent := c.evictList.Back(); ent != nil && !c.closed; ent = ent.Prev() {
+		e := ent.Value.(*entry)
+		entries = append(entries, ranged{e.key, e.value, e.updated})
+	}
+	c.lock.RUnlock()
+
+	for _, r := range entries {
+		if !fn(r.key, r.value, r.updated) {
+			return
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRange(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Hour)
+	rl.now = clock.Now
+
+	rl.Incr("foo", 5)
+	rl.Incr("bar", 5)
+	rl.Incr("bar", 5)
+
+	var keys []interface{}
+	rl.Range(func(key interface{}, value uint64, updated time.Time) bool {
+		if key == "bar" && value != 2 {
+			t.Fatalf("expected bar to be [2], got [%d]", value)
+		}
+		if !updated.Equal(clock.Now()) {
+			t.Fatalf("expected the window start to be passed along, got [%v]", updated)
+		}
+		keys = append(keys, key)
+		rl.Incr("baz", 5)
+		return true
+	})
+	if len(keys) != 2 || keys[0] != "foo" || keys[1] != "bar" {
+		t.Fatalf("expected foo then bar, got [%v]", keys)
+	}
+}
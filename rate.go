@@ -0,0 +1,97 @@
+package ratelimiter
+
+import "time"
+
+// rateMeter counts increments in one second buckets and remembers the busiest
+type rateMeter struct {
+	bucket time.Time
+	count  uint64
+
+	// count for the second before bucket, for CurrentRate
+	previous uint64
+
+	peak   uint64
+	peakAt time.Time
+}
+
+// observe counts an increment at now
+func (r *rateMeter) observe(now time.Time) {
+	r.roll(now)
+	r.count++
+	if r.count > r.peak {
+		r.peak = r.count
+		r.peakAt = r.bucket
+	}
+}
+
+// roll moves the meter on to the second now is in
+func (r *rateMeter) roll(now time.Time) {
+	sec := now.Truncate(time.Second)
+	if sec.Equal(r.bucket) {
+		return
+	}
+	if sec.Sub(r.bucket) == time.Second {
+		r.previous = r.count
+	} else {
+		r.previous = 0
+	}
+	r.bucket = sec
+	r.count = 0
+}
+
+// current returns the increments in the second leading up to now, counting the part of
+// the previous bucket still inside that second as if it was spread evenly over it
+func (r *rateMeter) current(now time.Time) float64 {
+	r.roll(now)
+	overlap := 1 - float64(now.Sub(r.bucket))/float64(time.Second)
+	return float64(r.count) + float64(r.previous)*overlap
+}
+
+// WithRateTracking measures how many increments per second the whole cache handles, for PeakStats
+// and CurrentRate. Every Incr reads the clock to find its one second bucket.
+func WithRateTracking() Option {
+	return func(c *Cache) {
+		c.rate = &rateMeter{}
+	}
+}
+
+// PeakStats returns the most entries the cache has held at once and the highest number of increments
+// it's seen in a single second, along with the second that happened in. maxRate and at are only
+// tracked WithRateTracking. Both peaks cover the life of the cache or since the last ResetPeakStats.
+func (c *Cache) PeakStats() (maxLen int, maxRate float64, at time.Time) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.rate != nil {
+		maxRate, at = float64(c.rate.peak), c.rate.peakAt
+	}
+	return c.peakLen, maxRate, at
+}
+
+// ResetPeakStats starts PeakStats over from the cache as it is right now.
+func (c *Cache) ResetPeakStats() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.peakLen = c.evictList.Len()
+	if c.rate != nil {
+		c.rate.peak = 0
+		c.rate.peakAt = time.Time{}
+	}
+}
+
+// CurrentRate returns how many increments per second the whole cache is handling, counted over the
+// last second. It's only tracked WithRateTracking and is zero otherwise.
+func (c *Cache) CurrentRate() float64 {
+	// the write lock since reading can move the meter on to the current second
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.rate == nil {
+		return 0
+	}
+	return c.rate.current(c.now())
+}
@@ -0,0 +1,102 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPeakStats(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(100, time.Hour, WithRateTracking())
+	rl.now = clock.Now
+	start := clock.Now()
+
+	// 20 keys in the first second, then drop back to 5
+	for i := 0; i < 20; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 1000)
+	}
+	for i := 5; i < 20; i++ {
+		rl.Remove(fmt.Sprintf("foo_%d", i))
+	}
+
+	// a busier second with 50 increments on existing keys
+	clock.Add(time.Second)
+	for i := 0; i < 50; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i%5), 1000)
+	}
+
+	// and a quieter one after
+	clock.Add(time.Second)
+	for i := 0; i < 10; i++ {
+		_, _ = rl.Incr("foo_0", 1000)
+	}
+
+	maxLen, maxRate, at := rl.PeakStats()
+	if maxLen != 20 {
+		t.Fatalf("expected a peak of [20] entries got [%d]", maxLen)
+	}
+	if maxRate != 50 {
+		t.Fatalf("expected a peak rate of [50] per second got [%f]", maxRate)
+	}
+	if !at.Equal(start.Add(time.Second)) {
+		t.Fatalf("expected the peak in the second second, got [%s]", at.Sub(start))
+	}
+
+	rl.ResetPeakStats()
+	maxLen, maxRate, _ = rl.PeakStats()
+	if maxLen != 5 || maxRate != 0 {
+		t.Fatalf("expected peaks to start over from [5] entries, got [%d] and [%f]", maxLen, maxRate)
+	}
+
+	_, _ = rl.Incr("foo_0", 1000)
+	if _, maxRate, _ = rl.PeakStats(); maxRate != 11 {
+		t.Fatalf("expected the peak rate to pick up the current second after a reset, got [%f]", maxRate)
+	}
+}
+
+func TestPeakStatsWithoutRateTracking(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("bar", 10)
+
+	maxLen, maxRate, at := rl.PeakStats()
+	if maxLen != 2 || maxRate != 0 || !at.IsZero() {
+		t.Fatalf("expected only the peak length without rate tracking, got [%d] [%f] [%s]", maxLen, maxRate, at)
+	}
+}
+
+func TestCurrentRate(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(100, time.Hour, WithRateTracking())
+	rl.now = clock.Now
+
+	if rate := rl.CurrentRate(); rate != 0 {
+		t.Fatalf("expected no rate before any increments, got [%f]", rate)
+	}
+
+	// a burst of 100 in the first tenth of a second
+	for i := 0; i < 100; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i%10), 1000)
+	}
+	clock.Add(100 * time.Millisecond)
+	if rate := rl.CurrentRate(); rate != 100 {
+		t.Fatalf("expected a rate of [100] straight after the burst, got [%f]", rate)
+	}
+
+	// as the burst slides out of the last second the rate decays
+	clock.Add(time.Second)
+	rate := rl.CurrentRate()
+	if rate < 85 || rate > 95 {
+		t.Fatalf("expected the rate to decay to about [90], got [%f]", rate)
+	}
+	clock.Add(500 * time.Millisecond)
+	if decayed := rl.CurrentRate(); decayed >= rate {
+		t.Fatalf("expected the rate to keep decaying from [%f], got [%f]", rate, decayed)
+	}
+
+	clock.Add(time.Second)
+	if rate := rl.CurrentRate(); rate != 0 {
+		t.Fatalf("expected the rate to drop to [0] once the burst is over a second old, got [%f]", rate)
+	}
+}
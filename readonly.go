@@ -0,0 +1,55 @@
+package ratelimiter
+
+// Stats is a summary of the cache size and activity since it was created
+type Stats struct {
+	Len        int
+	Capacity   int
+	Evictions  uint64
+	Violations uint64
+}
+
+// Stats returns the cache size along with how many evictions and rate limit violations it's seen.
+func (c *Cache) Stats() Stats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	length := c.evictList.Len()
+	if c.closed {
+		length = 0
+	}
+	return Stats{
+		Len:        length,
+		Capacity:   c.MaxEntries,
+		Evictions:  c.counters.evictions,
+		Violations: c.counters.violations,
+	}
+}
+
+// ReadOnlyCache is the subset of Cache for components that should observe counts but never change them.
+// Get still marks keys as recently used, use Peek to avoid touching recency as well.
+type ReadOnlyCache interface {
+	Get(key interface{}) (value uint64, ok bool)
+	Peek(key interface{}) (value uint64, ok bool)
+	Contains(key interface{}) bool
+	Len() int
+	Keys() []interface{}
+	Stats() Stats
+}
+
+// readOnly hides the Cache behind ReadOnlyCache so it can't be type asserted back
+type readOnly struct {
+	c *Cache
+}
+
+// ReadOnly returns a view of the cache that only allows reads, it shares the cache's
+// storage and locks so it always reflects the live contents.
+func (c *Cache) ReadOnly() ReadOnlyCache {
+	return readOnly{c}
+}
+
+func (r readOnly) Get(key interface{}) (uint64, bool)  { return r.c.Get(key) }
+func (r readOnly) Peek(key interface{}) (uint64, bool) { return r.c.Peek(key) }
+func (r readOnly) Contains(key interface{}) bool       { return r.c.Contains(key) }
+func (r readOnly) Len() int                            { return r.c.Len() }
+func (r readOnly) Keys() []interface{}                 { return r.c.Keys() }
+func (r readOnly) Stats() Stats                        { return r.c.Stats() }
@@ -0,0 +1,79 @@
+package ratelimiter
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestReadOnlyReflectsLiveChanges(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	ro := rl.ReadOnly()
+
+	if ro.Contains("foo") || ro.Len() != 0 {
+		t.Fatalf("expected an empty read-only view")
+	}
+
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("bar", 1)
+	_, _ = rl.Incr("bar", 1)
+
+	if cnt, ok := ro.Get("foo"); !ok || cnt != 2 {
+		t.Fatalf("expected the view to see foo with a count of [2] but got [%d]", cnt)
+	}
+	if cnt, ok := ro.Peek("bar"); !ok || cnt != 2 {
+		t.Fatalf("expected the view to peek bar with a count of [2] but got [%d]", cnt)
+	}
+	if !ro.Contains("bar") || ro.Len() != 2 {
+		t.Fatalf("expected the view to contain [2] keys, has [%d]", ro.Len())
+	}
+	if keys := ro.Keys(); len(keys) != 2 || keys[0] != "bar" || keys[1] != "foo" {
+		t.Fatalf("expected keys oldest first as [bar foo] got %v", keys)
+	}
+	if stats := ro.Stats(); stats.Len != 2 || stats.Capacity != 10 || stats.Violations != 1 {
+		t.Fatalf("expected stats for [2] of [10] keys with [1] violation, got %+v", stats)
+	}
+
+	rl.Remove("foo")
+	if ro.Contains("foo") {
+		t.Fatalf("expected the view to see foo removed")
+	}
+}
+
+// the view's type shouldn't offer anything that changes the cache
+func TestReadOnlyHasNoMutators(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	ro := rl.ReadOnly()
+
+	if _, ok := ro.(*Cache); ok {
+		t.Fatalf("expected the read-only view not to be the cache itself")
+	}
+
+	var methods []string
+	typ := reflect.TypeOf((*ReadOnlyCache)(nil)).Elem()
+	for i := 0; i < typ.NumMethod(); i++ {
+		methods = append(methods, typ.Method(i).Name)
+	}
+	sort.Strings(methods)
+
+	want := []string{"Contains", "Get", "Keys", "Len", "Peek", "Stats"}
+	if !reflect.DeepEqual(methods, want) {
+		t.Fatalf("expected read-only methods %v got %v", want, methods)
+	}
+}
+
+func TestPeekDoesntTouchRecency(t *testing.T) {
+	rl, _ := New(2, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("bar", 10)
+
+	_, _ = rl.Peek("foo")
+	_ = rl.Contains("foo")
+	_, _ = rl.Incr("baz", 10)
+
+	if rl.Contains("foo") {
+		t.Fatalf("expected foo to be evicted since Peek and Contains don't mark it as used")
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Registry keeps a set of named caches, for services running several limiters that share configuration.
+// It is safe for concurrent access.
+type Registry struct {
+
+	// OnEvicted is the eviction callback caches created by the registry start with,
+	// a cache can still set its own OnEvicted afterwards to override it.
+	OnEvicted func(key interface{}, value interface{})
+
+	caches map[string]*Cache
+	lock   sync.Mutex
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{caches: make(map[string]*Cache)}
+}
+
+// New creates a cache the same as the package level New and registers it under name, it fails if
+// there's already a cache with that name. The cache starts with the registry's OnEvicted.
+func (r *Registry) New(name string, maxEntries int, ratePeriod time.Duration, opts ...Option) (*Cache, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.caches[name]; ok {
+		return nil, fmt.Errorf("A cache named [%s] is already registered", name)
+	}
+
+	c, err := New(maxEntries, ratePeriod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.OnEvicted = r.OnEvicted
+	r.caches[name] = c
+	return c, nil
+}
+
+// Get returns the cache registered under name.
+func (r *Registry) Get(name string) (*Cache, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	c, ok := r.caches[name]
+	return c, ok
+}
+
+// Remove unregisters the cache under name, the cache itself keeps working for anyone still holding it.
+func (r *Registry) Remove(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.caches, name)
+}
@@ -0,0 +1,57 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistryDefaultOnEvicted(t *testing.T) {
+	reg := NewRegistry()
+
+	var defaults, overrides []interface{}
+	reg.OnEvicted = func(key interface{}, value interface{}) {
+		defaults = append(defaults, key)
+	}
+
+	inherits, err := reg.New("inherits", 1, time.Hour)
+	if err != nil {
+		t.Fatalf("unable to create cache: %v", err)
+	}
+	overridden, _ := reg.New("overridden", 1, time.Hour)
+	overridden.OnEvicted = func(key interface{}, value interface{}) {
+		overrides = append(overrides, key)
+	}
+
+	for _, key := range []string{"foo", "bar"} {
+		_, _ = inherits.Incr(key, 10)
+		_, _ = overridden.Incr(key, 10)
+	}
+
+	if len(defaults) != 1 || defaults[0] != "foo" {
+		t.Fatalf("expected the registry default to see foo evicted once, got %v", defaults)
+	}
+	if len(overrides) != 1 || overrides[0] != "foo" {
+		t.Fatalf("expected the per cache override to see foo evicted once, got %v", overrides)
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	reg := NewRegistry()
+
+	c, _ := reg.New("api", 10, time.Hour)
+	if _, err := reg.New("api", 10, time.Hour); err == nil {
+		t.Fatalf("expected registering a duplicate name to fail")
+	}
+	if _, err := reg.New("bad", 0, time.Hour); err == nil {
+		t.Fatalf("expected an invalid size to fail the same as New")
+	}
+
+	if got, ok := reg.Get("api"); !ok || got != c {
+		t.Fatalf("expected to get back the registered cache")
+	}
+
+	reg.Remove("api")
+	if _, ok := reg.Get("api"); ok {
+		t.Fatalf("expected the cache to be unregistered")
+	}
+}
@@ -0,0 +1,44 @@
+package ratelimiter
+
+import "time"
+
+// Event is a single recorded increment of Key at Time, for Replay
+type Event struct {
+	Key  interface{}
+	Time time.Time
+}
+
+// AllowAtTime increments key the same as Incr but as if the current time were t, and reports
+// whether it's under the rate limit. It's meant for replaying recorded traffic, times should
+// be fed in order since rate periods only ever move forward.
+func (c *Cache) AllowAtTime(key interface{}, maxValue int, t time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.allowAtTime(key, maxValue, t)
+}
+
+// allowAtTime is AllowAtTime for callers that already hold the write lock, everything that reads
+// the clock holds the lock too so it's safe to swap it for the duration of the increment
+func (c *Cache) allowAtTime(key interface{}, maxValue int, t time.Time) bool {
+	now := c.now
+	c.now = func() time.Time { return t }
+	defer func() { c.now = now }()
+
+	_, underRateLimit := c.incr(key, maxValue)
+	return underRateLimit
+}
+
+// Replay runs every event through AllowAtTime in order under a single lock and returns the
+// allow or deny decision for each. Replaying into a new cache created with the settings being
+// evaluated is a way to check a policy against historical traffic.
+func (c *Cache) Replay(events []Event, maxValue int) []bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	decisions := make([]bool, len(events))
+	for i, ev := range events {
+		decisions[i] = c.allowAtTime(ev.Key, maxValue, ev.Time)
+	}
+	return decisions
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	events := []Event{
+		{"foo", at(0)},
+		{"foo", at(1)},
+		{"bar", at(1)},
+		{"foo", at(2)}, // third in the period, over a max of 2
+		{"foo", at(9)},
+		{"bar", at(9)},
+		{"foo", at(11)}, // the period is over so foo starts fresh
+		{"foo", at(12)},
+		{"foo", at(13)},
+		{"bar", at(14)}, // bar's period started at 1s and is over too
+	}
+	want := []bool{true, true, true, false, false, true, true, true, false, true}
+
+	got := rl.Replay(events, 2)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected decisions %v got %v", want, got)
+	}
+
+	// replaying doesn't leave the cache on the replayed clock
+	if rl.now().Before(time.Now().Add(-time.Minute)) {
+		t.Fatalf("expected the cache clock to be restored after a replay")
+	}
+}
+
+func TestAllowAtTime(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !rl.AllowAtTime("foo", 1, start) {
+		t.Fatalf("expected the first increment to be allowed")
+	}
+	if rl.AllowAtTime("foo", 1, start.Add(5*time.Second)) {
+		t.Fatalf("expected the second increment inside the period to be denied")
+	}
+	if !rl.AllowAtTime("foo", 1, start.Add(11*time.Second)) {
+		t.Fatalf("expected an increment after the period to be allowed")
+	}
+}
@@ -0,0 +1,66 @@
+package ratelimiter
+
+// Reserve tentatively adds cost to key's count, for operations that should only be charged if they
+// succeed. ok is false, and nothing is added, if cost would take the count, along with any counts
+// merged from other nodes by MergeCRDT, over maxValue for the current rate period. A key that isn't
+// cached is only added once its reservation succeeds. Otherwise call commit to keep the charge or
+// refund to hand it back. Only the first of the two to be called has any effect and calling either
+// again does nothing. A refund after the key's rate period has started over is dropped, since the
+// charge went with the old period.
+func (c *Cache) Reserve(key interface{}, cost uint64, maxValue int) (commit func(), refund func(), ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return nil, nil, false
+	}
+
+	now := c.now()
+	if _, ok := c.find(key); !ok {
+		// check against the limit a new entry would start with, so a refused reservation
+		// doesn't add the key or evict another one to make room for it
+		if c.full(key) || cost > uint64(c.effectiveLimit(&entry{created: now}, maxValue)) {
+			return nil, nil, false
+		}
+	}
+
+	e := c.lookup(key)
+	if c.expired(e, now) {
+		c.rollover(e, key, e.value, now)
+		e.value = 0
+	}
+	// written to not overflow on a huge cost
+	limit, used := uint64(c.effectiveLimit(e, maxValue)), e.value+e.remoteTotal
+	if used > limit || cost > limit-used {
+		return nil, nil, false
+	}
+	e.value += cost
+	e.total += cost
+
+	window := e.started
+	settled := false
+	commit = func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		settled = true
+	}
+	refund = func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if settled {
+			return
+		}
+		settled = true
+		if !e.started.Equal(window) {
+			return
+		}
+		if e.value < cost {
+			e.value = 0
+		} else {
+			e.value -= cost
+		}
+		e.total -= cost
+	}
+	return commit, refund, true
+}
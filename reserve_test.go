@@ -0,0 +1,123 @@
+package ratelimiter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReserveCommit(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	commit, refund, ok := rl.Reserve("foo", 4, 10)
+	if !ok {
+		t.Fatalf("expected a reservation within the limit to succeed")
+	}
+	commit()
+	refund()
+
+	if cnt, _ := rl.Peek("foo"); cnt != 4 {
+		t.Fatalf("expected a committed reservation to stay deducted at [4] got [%d]", cnt)
+	}
+
+	// 4 + 7 is over the limit, so nothing is taken
+	if _, _, ok := rl.Reserve("foo", 7, 10); ok {
+		t.Fatalf("expected a reservation over the limit to fail")
+	}
+	if cnt, _ := rl.Peek("foo"); cnt != 4 {
+		t.Fatalf("expected a failed reservation to leave the count at [4] got [%d]", cnt)
+	}
+}
+
+func TestReserveRefund(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+
+	commit, refund, ok := rl.Reserve("foo", 5, 10)
+	if !ok {
+		t.Fatalf("expected a reservation within the limit to succeed")
+	}
+	if cnt, _ := rl.Peek("foo"); cnt != 6 {
+		t.Fatalf("expected the reservation to be deducted up front, have [%d]", cnt)
+	}
+
+	refund()
+	refund()
+	commit()
+	if cnt, _ := rl.Peek("foo"); cnt != 1 {
+		t.Fatalf("expected a refund to restore the count to [1] exactly once, got [%d]", cnt)
+	}
+	if total, _ := rl.Lifetime("foo"); total != 1 {
+		t.Fatalf("expected a refund to come off the lifetime total too, got [%d]", total)
+	}
+}
+
+// a refund for a period that's already over shouldn't eat into the new one
+func TestReserveRefundAfterReset(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	_, refund, _ := rl.Reserve("foo", 5, 10)
+	clock.Add(11 * time.Second)
+	if _, _, ok := rl.Reserve("foo", 3, 10); !ok {
+		t.Fatalf("expected a reservation in the new period to succeed")
+	}
+
+	refund()
+	if cnt, _ := rl.Peek("foo"); cnt != 3 {
+		t.Fatalf("expected a stale refund to be dropped leaving [3] got [%d]", cnt)
+	}
+}
+
+// an expired period starts over the same way Incr does it
+func TestReserveRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	var audited []AuditRecord
+	rl.OnAudit = func(record AuditRecord) {
+		audited = append(audited, record)
+	}
+	var previous uint64
+	rl.SetResetHandler("foo", func(p uint64) {
+		previous = p
+	})
+
+	_, _, _ = rl.Reserve("foo", 3, 10)
+	rl.MergeCRDT("node-b", []KeyCount{{"foo", 6}})
+	if _, _, ok := rl.Reserve("foo", 2, 10); ok {
+		t.Fatalf("expected the merged count to take the reservation over the limit")
+	}
+
+	clock.Add(11 * time.Second)
+	if _, _, ok := rl.Reserve("foo", 2, 10); !ok {
+		t.Fatalf("expected a reservation in the new period to succeed")
+	}
+	if previous != 3 || len(audited) != 1 || audited[0].Count != 3 || rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be reported, got [%d] %v", previous, audited)
+	}
+	if count, _ := rl.MergedCount("foo"); count != 2 {
+		t.Fatalf("expected the merged counts to go with the old period, got [%d]", count)
+	}
+
+	// a huge cost doesn't wrap around under the limit
+	if _, _, ok := rl.Reserve("foo", math.MaxUint64, 10); ok {
+		t.Fatalf("expected an overflowing cost to be refused")
+	}
+}
+
+// a refused reservation for a new key doesn't push a live one out
+func TestReserveRefusedNewKey(t *testing.T) {
+	rl, _ := New(2, time.Hour)
+	rl.Incr("a", 10)
+	rl.Incr("b", 10)
+
+	if _, _, ok := rl.Reserve("c", 100, 5); ok {
+		t.Fatalf("expected a reservation over the limit to be refused")
+	}
+	if keys := rl.Keys(); len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("expected the refused key not to be added, got %v", keys)
+	}
+}
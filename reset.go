@@ -0,0 +1,115 @@
+package ratelimiter
+
+import "time"
+
+// Reset zeroes the count for key and starts a fresh rate period, the key stays cached.
+func (c *Cache) Reset(key interface{}) {
+	_, _ = c.ResetAndReport(key)
+}
+
+// ResetAll zeroes the count for every key in the cache.
+func (c *Cache) ResetAll() {
+	_ = c.ResetAllAndReport()
+}
+
+// ResetAndReport zeroes the count for key the same as Reset and returns the count
+// it had beforehand, ok is false if the key isn't in the cache.
+func (c *Cache) ResetAndReport(key interface{}) (previous uint64, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.find(key); ok {
+		e := ent.Value.(*entry)
+		previous = e.value
+		c.resetEntry(e)
+		return previous, true
+	}
+	return
+}
+
+// ResetIfOlderThan resets key the same as Reset, but only if its current rate period began more
+// than age ago, checked and reset atomically. ok is false if the key isn't in the cache.
+func (c *Cache) ResetIfOlderThan(key interface{}, age time.Duration) (wasReset bool, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return false, false
+	}
+	ent, ok := c.find(key)
+	if !ok {
+		return false, false
+	}
+	if e := ent.Value.(*entry); c.now().Sub(e.updated) > age {
+		c.resetEntry(e)
+		return true, true
+	}
+	return false, true
+}
+
+// ResetAllAndReport zeroes the count for every key the same as ResetAll and returns
+// the counts they had beforehand, most recently used first.
+func (c *Cache) ResetAllAndReport() []KeyCount {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	previous := make([]KeyCount, 0, c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		previous = append(previous, KeyCount{e.key, e.value})
+		c.resetEntry(e)
+	}
+	return previous
+}
+
+// ResetWhere resets every entry pred returns true for the same as Reset, keeping the keys cached,
+// and returns how many were reset. pred is called with the cache locked, so it mustn't call back into it.
+func (c *Cache) ResetWhere(pred func(key interface{}, value uint64) bool) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0
+	}
+	reset := 0
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		if pred(e.key, e.value) {
+			c.resetEntry(e)
+			reset++
+		}
+	}
+	return reset
+}
+
+// resetEntry zeroes an entry's count and starts its rate period over
+func (c *Cache) resetEntry(e *entry) {
+	c.counters.resets++
+	c.emit(EventReset, e, e.value)
+	e.value = 0
+	e.kinds = [numKinds]uint64{}
+	e.children, e.unique = nil, nil
+	c.clearRemote(e)
+	c.startWindow(e, c.now())
+	c.notifyAvailable(e)
+}
+
+// SetResetHandler sets fn to be called whenever Incr finds key's rate period is over and starts a
+// new one, with the count the finished period ended with. The key is added to the cache if it isn't
+// there already. The handler lives with the entry, so it's dropped if the key is evicted or removed,
+// and a nil fn clears it. It's called with the cache locked, so it mustn't call back into the cache.
+func (c *Cache) SetResetHandler(key interface{}, fn func(previous uint64)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return
+	}
+	c.lookup(key).onReset = fn
+}
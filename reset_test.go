@@ -0,0 +1,172 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetAndReport(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	key := "foo"
+	for i := 0; i < 47; i++ {
+		_, _ = rl.Incr(key, 100)
+	}
+
+	previous, ok := rl.ResetAndReport(key)
+	if !ok {
+		t.Fatalf("expected foo to be reset")
+	}
+	if previous != 47 {
+		t.Fatalf("expected a previous count of [47] but got [%d]", previous)
+	}
+
+	cnt, ok := rl.Get(key)
+	if !ok || cnt != 0 {
+		t.Fatalf("expected foo to still be cached with a count of [0] but got [%d]", cnt)
+	}
+
+	if _, ok := rl.ResetAndReport("bar"); ok {
+		t.Fatalf("expected resetting a missing key to report false")
+	}
+}
+
+func TestResetAllAndReport(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	counts := map[string]uint64{"foo": 3, "bar": 5, "baz": 1}
+	for key, cnt := range counts {
+		for i := uint64(0); i < cnt; i++ {
+			_, _ = rl.Incr(key, 100)
+		}
+	}
+
+	previous := rl.ResetAllAndReport()
+	if len(previous) != len(counts) {
+		t.Fatalf("expected [%d] reported keys but got [%d]", len(counts), len(previous))
+	}
+	for _, kc := range previous {
+		if counts[kc.Key.(string)] != kc.Count {
+			t.Fatalf("expected %s to report a previous count of [%d] but got [%d]", kc.Key, counts[kc.Key.(string)], kc.Count)
+		}
+	}
+
+	for key := range counts {
+		if cnt, _ := rl.Get(key); cnt != 0 {
+			t.Fatalf("expected %s to be zeroed but got [%d]", key, cnt)
+		}
+	}
+	if rl.Len() != len(counts) {
+		t.Fatalf("expected reset keys to stay cached, have [%d] of [%d]", rl.Len(), len(counts))
+	}
+}
+
+func TestResetWhere(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	counts := map[string]int{"foo": 12, "bar": 3, "baz": 15, "qux": 1}
+	for key, cnt := range counts {
+		for i := 0; i < cnt; i++ {
+			_, _ = rl.Incr(key, 10)
+		}
+	}
+
+	reset := rl.ResetWhere(func(key interface{}, value uint64) bool {
+		return value > 10
+	})
+	if reset != 2 {
+		t.Fatalf("expected the [2] keys over the limit to be reset, reset [%d]", reset)
+	}
+
+	for key, cnt := range counts {
+		want := uint64(cnt)
+		if cnt > 10 {
+			want = 0
+		}
+		got, ok := rl.Peek(key)
+		if !ok || got != want {
+			t.Fatalf("expected %s to have a count of [%d] but got [%d]", key, want, got)
+		}
+	}
+
+	// the reset keys are under the limit again
+	if _, underRateLimit := rl.Incr("foo", 10); !underRateLimit {
+		t.Fatalf("expected foo to be under the limit after being reset")
+	}
+}
+
+func TestSetResetHandler(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(2, 10*time.Second)
+	rl.now = clock.Now
+
+	var resets []uint64
+	rl.SetResetHandler("paid", func(previous uint64) {
+		resets = append(resets, previous)
+	})
+
+	maxCount := 3
+	for i := 0; i < 5; i++ {
+		_, _ = rl.Incr("paid", maxCount)
+		_, _ = rl.Incr("free", maxCount)
+	}
+	if len(resets) != 0 {
+		t.Fatalf("expected no resets inside the rate period, got %v", resets)
+	}
+
+	clock.Add(11 * time.Second)
+	_, _ = rl.Incr("paid", maxCount)
+	if cnt, underRateLimit := rl.Incr("free", maxCount); !underRateLimit || cnt != 1 {
+		t.Fatalf("expected a key without a handler to reset normally, got count [%d]", cnt)
+	}
+	if len(resets) != 1 || resets[0] != 5 {
+		t.Fatalf("expected the handler to fire once with the finished period's count [5], got %v", resets)
+	}
+
+	// the handler goes with the entry when it's evicted
+	_, _ = rl.Incr("other", maxCount)
+	_, _ = rl.Incr("other2", maxCount)
+	for i := 0; i < 5; i++ {
+		_, _ = rl.Incr("paid", maxCount)
+	}
+	clock.Add(11 * time.Second)
+	_, _ = rl.Incr("paid", maxCount)
+	if len(resets) != 1 {
+		t.Fatalf("expected the handler to be dropped on eviction, got %v", resets)
+	}
+}
+
+func TestResetIfOlderThan(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Hour)
+	rl.now = clock.Now
+
+	if wasReset, ok := rl.ResetIfOlderThan("foo", time.Minute); wasReset || ok {
+		t.Fatalf("expected a missing key to report [false] [false], got [%t] [%t]", wasReset, ok)
+	}
+
+	rl.Incr("foo", 10)
+	rl.Incr("foo", 10)
+	clock.Add(time.Minute)
+
+	// a window exactly age old isn't older than it
+	if wasReset, ok := rl.ResetIfOlderThan("foo", time.Minute); wasReset || !ok {
+		t.Fatalf("expected a fresh window to be left alone, got [%t] [%t]", wasReset, ok)
+	}
+	if value, _ := rl.Peek("foo"); value != 2 {
+		t.Fatalf("expected the count to be untouched, got [%d]", value)
+	}
+
+	clock.Add(time.Second)
+	if wasReset, ok := rl.ResetIfOlderThan("foo", time.Minute); !wasReset || !ok {
+		t.Fatalf("expected an old window to be reset, got [%t] [%t]", wasReset, ok)
+	}
+	if value, _ := rl.Peek("foo"); value != 0 {
+		t.Fatalf("expected the count to be zeroed, got [%d]", value)
+	}
+
+	// the reset started a fresh window
+	if wasReset, _ := rl.ResetIfOlderThan("foo", time.Minute); wasReset {
+		t.Fatalf("expected the new window to be too young to reset")
+	}
+}
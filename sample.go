@@ -0,0 +1,55 @@
+package ratelimiter
+
+import "math/rand"
+
+// reservoir keeps a uniform random sample of every entry it's offered using reservoir sampling,
+// so it never holds more than its size however many are offered
+type reservoir struct {
+	samples []KeyCount
+	size    int
+	seen    int64
+}
+
+// add offers an entry to the sample
+func (r *reservoir) add(e *entry) {
+	r.seen++
+	if len(r.samples) < r.size {
+		r.samples = append(r.samples, KeyCount{e.key, e.value})
+		return
+	}
+	if i := rand.Int63n(r.seen); i < int64(r.size) {
+		r.samples[i] = KeyCount{e.key, e.value}
+	}
+}
+
+// WithEvictionSampling keeps a uniform random sample of up to size of the entries evicted for
+// capacity or by the janitor, so heavy eviction can be analysed without logging every key.
+func WithEvictionSampling(size int) Option {
+	return func(c *Cache) {
+		if size > 0 {
+			c.evictionSample = &reservoir{size: size}
+		}
+	}
+}
+
+// EvictionSample returns up to n evicted keys with the count they had when evicted, drawn uniformly
+// from every eviction so far. It's empty unless the cache was created WithEvictionSampling, and never
+// returns more than the size given there.
+func (c *Cache) EvictionSample(n int) []KeyCount {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.evictionSample == nil || n <= 0 {
+		return nil
+	}
+	if n > len(c.evictionSample.samples) {
+		n = len(c.evictionSample.samples)
+	}
+	// a partial Fisher-Yates shuffle of a copy picks n of the reservoir uniformly
+	sample := append([]KeyCount(nil), c.evictionSample.samples...)
+	for i := 0; i < n; i++ {
+		j := i + rand.Intn(len(sample)-i)
+		sample[i], sample[j] = sample[j], sample[i]
+	}
+	return sample[:n]
+}
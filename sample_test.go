@@ -0,0 +1,88 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEvictionSample(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithEvictionSampling(20))
+
+	// each key is incremented i%3+1 times so we know what count it was evicted with
+	evicted := map[interface{}]uint64{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		var i int
+		fmt.Sscanf(key.(string), "foo_%d", &i)
+		evicted[key] = uint64(i%3 + 1)
+	}
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("foo_%d", i)
+		for j := 0; j <= i%3; j++ {
+			_, _ = rl.Incr(key, 10)
+		}
+	}
+	if len(evicted) != 990 {
+		t.Fatalf("expected [990] evictions, got [%d]", len(evicted))
+	}
+
+	sample := rl.EvictionSample(50)
+	if len(sample) != 20 {
+		t.Fatalf("expected the sample to be capped at [20], got [%d]", len(sample))
+	}
+	if sample = rl.EvictionSample(5); len(sample) != 5 {
+		t.Fatalf("expected a sample of [5], got [%d]", len(sample))
+	}
+
+	sample = rl.EvictionSample(20)
+	late := 0
+	for _, kc := range sample {
+		cnt, ok := evicted[kc.Key]
+		if !ok {
+			t.Fatalf("expected sampled key [%v] to have been evicted", kc.Key)
+		}
+		if cnt != kc.Count {
+			t.Fatalf("expected sampled key [%v] to have its evicted count [%d] got [%d]", kc.Key, cnt, kc.Count)
+		}
+		var i int
+		fmt.Sscanf(kc.Key.(string), "foo_%d", &i)
+		if i >= 500 {
+			late++
+		}
+	}
+	// a uniform sample shouldn't be stuck on the first evictions
+	if late == 0 {
+		t.Fatalf("expected the sample to include later evictions, got %v", sample)
+	}
+}
+
+// while the reservoir is still filling it's in eviction order, a subset mustn't just be the oldest
+func TestEvictionSampleSubset(t *testing.T) {
+	rl, _ := New(1, time.Hour, WithEvictionSampling(5))
+	for i := 0; i < 6; i++ {
+		_, _ = rl.Incr(i, 10)
+	}
+
+	seen := map[interface{}]bool{}
+	for i := 0; i < 200; i++ {
+		sample := rl.EvictionSample(1)
+		if len(sample) != 1 {
+			t.Fatalf("expected a sample of [1], got [%d]", len(sample))
+		}
+		seen[sample[0].Key] = true
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected every evicted key to turn up in a subset, got %v", seen)
+	}
+}
+
+func TestEvictionSampleDisabled(t *testing.T) {
+	rl, _ := New(1, time.Hour)
+	_, _ = rl.Incr("foo", 10)
+	_, _ = rl.Incr("bar", 10)
+
+	if sample := rl.EvictionSample(10); len(sample) != 0 {
+		t.Fatalf("expected no sample without WithEvictionSampling, got %v", sample)
+	}
+}
@@ -0,0 +1,79 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sharded spreads keys over several caches, each with its own lock, so increments on
+// different keys mostly don't contend with each other. It is safe for concurrent access.
+type Sharded struct {
+	shards []*Cache
+}
+
+// NewSharded creates a Sharded with the given number of shards. maxEntries is split evenly
+// between them and every shard is created with ratePeriod and opts the same as New.
+func NewSharded(shards, maxEntries int, ratePeriod time.Duration, opts ...Option) (*Sharded, error) {
+	if shards <= 0 {
+		return nil, fmt.Errorf("Must have at least one shard, got [%d]", shards)
+	}
+
+	s := &Sharded{shards: make([]*Cache, shards)}
+	for i := range s.shards {
+		perShard := maxEntries / shards
+		if maxEntries > 0 && perShard == 0 {
+			perShard = 1
+		}
+		c, err := New(perShard, ratePeriod, opts...)
+		if err != nil {
+			return nil, err
+		}
+		s.shards[i] = c
+	}
+	return s, nil
+}
+
+// shard returns the cache that holds key
+func (s *Sharded) shard(key interface{}) *Cache {
+	return s.shards[hashKey(key)%uint64(len(s.shards))]
+}
+
+// Incr increments key the same as Cache.Incr on the shard it belongs to.
+func (s *Sharded) Incr(key interface{}, maxValue int) (uint64, bool) {
+	return s.shard(key).Incr(key, maxValue)
+}
+
+// Get looks up a key's value the same as Cache.Get.
+func (s *Sharded) Get(key interface{}) (value uint64, ok bool) {
+	return s.shard(key).Get(key)
+}
+
+// Remove removes the provided key from its shard.
+func (s *Sharded) Remove(key interface{}) {
+	s.shard(key).Remove(key)
+}
+
+// Len returns the number of items across all shards.
+func (s *Sharded) Len() int {
+	var n int
+	for _, c := range s.shards {
+		n += c.Len()
+	}
+	return n
+}
+
+// Range calls fn for every entry in every shard, stopping as soon as fn returns false. Each shard is
+// only locked long enough to copy its entries, so fn is free to call back into the Sharded, and a
+// shard's view is consistent with itself but not with shards visited before or after it.
+func (s *Sharded) Range(fn func(key interface{}, value uint64, updated time.Time) bool) {
+	for _, c := range s.shards {
+		more := true
+		c.Range(func(key interface{}, value uint64, updated time.Time) bool {
+			more = fn(key, value, updated)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShardedRange(t *testing.T) {
+	s, _ := NewSharded(4, 100, time.Hour)
+
+	for i := 0; i < 40; i++ {
+		for j := 0; j <= i%3; j++ {
+			s.Incr(fmt.Sprintf("foo_%d", i), 100)
+		}
+	}
+	for i, c := range s.shards {
+		if c.Len() == 0 {
+			t.Fatalf("expected the keys to be spread over every shard, shard [%d] is empty", i)
+		}
+	}
+
+	seen := make(map[interface{}]uint64)
+	s.Range(func(key interface{}, value uint64, updated time.Time) bool {
+		seen[key] = value
+		// calling back in mustn't deadlock
+		s.Get(key)
+		return true
+	})
+	if len(seen) != 40 {
+		t.Fatalf("expected Range to visit all [40] keys, saw [%d]", len(seen))
+	}
+	for i := 0; i < 40; i++ {
+		if v := seen[fmt.Sprintf("foo_%d", i)]; v != uint64(i%3+1) {
+			t.Fatalf("expected foo_%d to be [%d], got [%d]", i, i%3+1, v)
+		}
+	}
+
+	var visits int
+	s.Range(func(key interface{}, value uint64, updated time.Time) bool {
+		visits++
+		return visits < 15
+	})
+	if visits != 15 {
+		t.Fatalf("expected Range to stop once fn returned false, visited [%d]", visits)
+	}
+}
+
+func TestNewShardedInvalid(t *testing.T) {
+	if _, err := NewSharded(0, 100, time.Hour); err == nil {
+		t.Fatalf("expected an error for zero shards")
+	}
+}
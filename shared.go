@@ -0,0 +1,95 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"time"
+)
+
+// budget is a counter shared by every key in a budget group
+type budget struct {
+	group   interface{}
+	count   uint64
+	updated time.Time
+}
+
+// IncrShared increments key against its own keyMax the same as Incr, and also increments the shared
+// counter for budgetGroup against sharedMax, so keys that belong together, like every API key of an
+// account, draw from one pool. The first result is whether the key is under its own limit and the
+// second whether the group is under the shared one, callers should only allow the request if both are.
+// Shared budgets have their own rate period of the same length, and it lifts the same way Incr does.
+// They aren't part of the cache's LRU but are kept in one of their own with room for MaxEntries
+// groups, so the least recently used group is dropped, and starts over, once there are more.
+func (c *Cache) IncrShared(key, budgetGroup interface{}, keyMax, sharedMax int) (bool, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || budgetGroup == nil || c.closed {
+		return false, false
+	}
+
+	_, keyOK := c.incr(key, keyMax)
+
+	now := c.now()
+	b := c.budget(budgetGroup, now)
+	b.count++
+	sharedOK := true
+	if b.count > uint64(sharedMax) {
+		if c.ratePeriod > 0 && now.Sub(b.updated) > c.ratePeriod {
+			b.count = 1
+			b.updated = c.windowStart(budgetGroup, now)
+		} else {
+			sharedOK = false
+		}
+	}
+	return keyOK, sharedOK
+}
+
+// budget returns the budget for group marking it as recently used, adding it if it's new and
+// dropping the least recently used one if that takes the budgets past MaxEntries
+func (c *Cache) budget(group interface{}, now time.Time) *budget {
+	if c.budgets == nil {
+		c.budgets = make(map[interface{}]*list.Element)
+		c.budgetOrder = list.New()
+	}
+	if ent, ok := c.budgets[group]; ok {
+		c.budgetOrder.MoveToFront(ent)
+		return ent.Value.(*budget)
+	}
+
+	if c.budgetOrder.Len() >= c.MaxEntries {
+		oldest := c.budgetOrder.Back()
+		c.budgetOrder.Remove(oldest)
+		delete(c.budgets, oldest.Value.(*budget).group)
+	}
+	b := &budget{group: group, updated: c.windowStart(group, now)}
+	c.budgets[group] = c.budgetOrder.PushFront(b)
+	return b
+}
+
+// SharedCount returns the current count of a shared budget group.
+func (c *Cache) SharedCount(budgetGroup interface{}) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return 0, false
+	}
+	if ent, ok := c.budgets[budgetGroup]; ok {
+		return ent.Value.(*budget).count, true
+	}
+	return 0, false
+}
+
+// RemoveBudget drops the shared counter for budgetGroup.
+func (c *Cache) RemoveBudget(budgetGroup interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.budgets[budgetGroup]; ok {
+		c.budgetOrder.Remove(ent)
+		delete(c.budgets, budgetGroup)
+	}
+}
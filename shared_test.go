@@ -0,0 +1,85 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIncrSharedKeyLimit(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	// a key's own limit blocks it while the account still has budget
+	for i := 1; i <= 3; i++ {
+		keyOK, sharedOK := rl.IncrShared("key1", "account", 2, 100)
+		if keyOK != (i <= 2) || !sharedOK {
+			t.Fatalf("expected increment [%d] to be key [%t] shared [true], got [%t] [%t]", i, i <= 2, keyOK, sharedOK)
+		}
+	}
+
+	// other keys in the account aren't affected
+	if keyOK, sharedOK := rl.IncrShared("key2", "account", 2, 100); !keyOK || !sharedOK {
+		t.Fatalf("expected another key in the account to be allowed")
+	}
+	if cnt, _ := rl.SharedCount("account"); cnt != 4 {
+		t.Fatalf("expected every member to draw from the shared count, have [%d]", cnt)
+	}
+}
+
+func TestIncrSharedBudget(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	members := []string{"key1", "key2", "key3"}
+	sharedMax := 5
+	for i := 0; i < sharedMax; i++ {
+		if _, sharedOK := rl.IncrShared(members[i%3], "account", 100, sharedMax); !sharedOK {
+			t.Fatalf("expected increment [%d] to be within the shared budget", i+1)
+		}
+	}
+
+	// exhausting the pool blocks every member even though each is well under its own limit
+	for _, key := range members {
+		keyOK, sharedOK := rl.IncrShared(key, "account", 100, sharedMax)
+		if !keyOK || sharedOK {
+			t.Fatalf("expected %s to pass its own limit but fail the shared one, got [%t] [%t]", key, keyOK, sharedOK)
+		}
+	}
+
+	// a different account has its own pool
+	if _, sharedOK := rl.IncrShared("key1", "other", 100, sharedMax); !sharedOK {
+		t.Fatalf("expected another budget group to be unaffected")
+	}
+
+	clock.Add(11 * time.Second)
+	if _, sharedOK := rl.IncrShared("key1", "account", 100, sharedMax); !sharedOK {
+		t.Fatalf("expected the shared budget to lift after the rate period")
+	}
+
+	rl.RemoveBudget("account")
+	if _, ok := rl.SharedCount("account"); ok {
+		t.Fatalf("expected the budget to be removed")
+	}
+}
+
+func TestIncrSharedBoundedBudgets(t *testing.T) {
+	rl, _ := New(3, time.Hour)
+	for i := 0; i < 100; i++ {
+		rl.IncrShared("key", i, 10, 10)
+	}
+	if len(rl.budgets) != 3 || rl.budgetOrder.Len() != 3 {
+		t.Fatalf("expected the budgets to be bounded at [3], have [%d]", len(rl.budgets))
+	}
+
+	// the least recently used groups go first
+	if _, ok := rl.SharedCount(96); ok {
+		t.Fatalf("expected an old budget group to be dropped")
+	}
+	if count, ok := rl.SharedCount(99); !ok || count != 1 {
+		t.Fatalf("expected the newest budget group to be kept, got [%d]", count)
+	}
+	rl.RemoveBudget(99)
+	if _, ok := rl.SharedCount(99); ok || rl.budgetOrder.Len() != 2 {
+		t.Fatalf("expected RemoveBudget to drop the group")
+	}
+}
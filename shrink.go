@@ -0,0 +1,23 @@
+package ratelimiter
+
+import "container/list"
+
+// ShrinkToFit rebuilds the cache's internal map and list sized to the entries it holds right now.
+// Go maps never give back memory as keys are deleted, so after removing a large share of the cache
+// this reclaims what the removed keys left behind. Contents and recency order are unchanged.
+func (c *Cache) ShrinkToFit() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	evictList := list.New()
+	cache := make(map[interface{}]*list.Element, c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		cache[e.id] = evictList.PushBack(e)
+	}
+	c.evictList = evictList
+	c.cache = cache
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// heapAlloc returns the live heap size after a collection
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+func TestShrinkToFit(t *testing.T) {
+	n := 100000
+	rl, _ := New(n, time.Hour)
+	for i := 0; i < n; i++ {
+		_, _ = rl.Incr(i, 10)
+	}
+	for i := 0; i < n-3; i++ {
+		rl.Remove(i)
+	}
+	_, _ = rl.Incr(n-3, 10)
+
+	before := heapAlloc()
+	rl.ShrinkToFit()
+	after := heapAlloc()
+
+	if after >= before {
+		t.Fatalf("expected shrinking to reclaim memory, heap went from [%d] to [%d] bytes", before, after)
+	}
+
+	if rl.Len() != 3 {
+		t.Fatalf("expected [3] entries to survive, have [%d]", rl.Len())
+	}
+	if cnt, _ := rl.Peek(n - 3); cnt != 2 {
+		t.Fatalf("expected key [%d] to keep its count of [2] but got [%d]", n-3, cnt)
+	}
+
+	// recency survives too, the oldest key is still evicted first
+	keys := rl.Keys()
+	if keys[0] != n-2 || keys[2] != n-3 {
+		t.Fatalf("expected recency to survive shrinking, got keys %v", keys)
+	}
+
+	// and the cache still works normally
+	rl.MaxEntries = 3
+	_, _ = rl.Incr("foo", 10)
+	if rl.Contains(n-2) || !rl.Contains("foo") {
+		t.Fatalf("expected the oldest key to be evicted after shrinking")
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimiter
+
+import "math/bits"
+
+const (
+	// sizeBuckets is the number of sizeSketch buckets, two for every power of two
+	sizeBuckets = 128
+
+	// sizeMinSamples is how many sizes the cache has to have seen overall before AllowBySize blocks anything
+	sizeMinSamples = 100
+
+	// sizeGlobalCap and sizeKeyCap are how many samples a sketch holds before its counts are halved,
+	// so old sizes fade out and the percentiles move with the traffic
+	sizeGlobalCap = 1 << 16
+	sizeKeyCap    = 1 << 10
+)
+
+// sizeSketch is a histogram of sizes in buckets half a power of two wide, so any
+// percentile it gives is within about 40% of the real one in a fixed amount of memory
+type sizeSketch struct {
+	buckets [sizeBuckets]uint32
+	count   uint64
+}
+
+// sizeBucket returns the bucket size falls in
+func sizeBucket(size uint64) int {
+	n := bits.Len64(size)
+	if n < 2 {
+		return n
+	}
+	return 2*n - 2 + int(size>>uint(n-2)&1)
+}
+
+// observe adds size, halving every count first if the sketch is full
+func (s *sizeSketch) observe(size uint64, cap uint64) {
+	if s.count >= cap {
+		s.count = 0
+		for i := range s.buckets {
+			s.buckets[i] /= 2
+			s.count += uint64(s.buckets[i])
+		}
+	}
+	s.buckets[sizeBucket(size)]++
+	s.count++
+}
+
+// quantile returns the bucket the p'th fraction of sizes are at or under
+func (s *sizeSketch) quantile(p float64) int {
+	target := uint64(p * float64(s.count))
+	var seen uint64
+	for i, n := range s.buckets {
+		seen += uint64(n)
+		if seen >= target && seen > 0 {
+			return i
+		}
+	}
+	return sizeBuckets - 1
+}
+
+// AllowBySize records a request of size for key and reports whether the key's typical request size,
+// its median, is still within the given percentile, from 0 to 100, of request sizes across the whole
+// cache. It's for keeping clients that send much bigger requests than everyone else in check without
+// picking a fixed limit. Both the cache wide and per key sizes are kept in compact sketches whose older
+// samples fade out over time, and sizes are only compared to within about 40%, so it suits catching
+// outliers rather than fine distinctions. Nothing is blocked until the cache has seen 100 sizes.
+func (c *Cache) AllowBySize(key interface{}, size uint64, percentile float64) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return false
+	}
+
+	e, ok := c.admit(key)
+	if !ok {
+		return false
+	}
+	if e.sizes == nil {
+		e.sizes = &sizeSketch{}
+	}
+	e.sizes.observe(size, sizeKeyCap)
+	c.sizes.observe(size, sizeGlobalCap)
+
+	if c.sizes.count < sizeMinSamples {
+		return true
+	}
+	return e.sizes.quantile(0.5) <= c.sizes.quantile(percentile/100)
+}
@@ -0,0 +1,59 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSizeBucket(t *testing.T) {
+	// buckets are half a power of two wide and only ever grow with size
+	for size, expected := range map[uint64]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 4, 6: 5, 7: 5, 8: 6, 1 << 63: 126, 1<<64 - 1: 127} {
+		if b := sizeBucket(size); b != expected {
+			t.Fatalf("expected size [%d] in bucket [%d], got [%d]", size, expected, b)
+		}
+	}
+	for size, last := uint64(1), 0; size < 1<<20; size++ {
+		b := sizeBucket(size)
+		if b < last {
+			t.Fatalf("expected bucket for [%d] not to go down from [%d], got [%d]", size, last, b)
+		}
+		last = b
+	}
+}
+
+func TestAllowBySize(t *testing.T) {
+	rl, _ := New(100, time.Hour)
+
+	// 50 clients sending typical requests of about 1KB
+	for i := 0; i < 1000; i++ {
+		size := uint64(800 + i%400)
+		if !rl.AllowBySize(fmt.Sprintf("client_%d", i%50), size, 99) {
+			t.Fatalf("expected typical traffic of size [%d] to pass", size)
+		}
+	}
+
+	// a client sending 100KB requests is blocked once its median is settled
+	var allowed bool
+	for i := 0; i < 5; i++ {
+		allowed = rl.AllowBySize("outlier", 100000, 99)
+	}
+	if allowed {
+		t.Fatalf("expected requests far above the 99th percentile to be blocked")
+	}
+
+	// an occasional large request from a typical client doesn't block it
+	rl.AllowBySize("client_0", 100000, 99)
+	if !rl.AllowBySize("client_0", 1000, 99) {
+		t.Fatalf("expected a single large request not to move a typical client's median")
+	}
+}
+
+func TestAllowBySizeMinSamples(t *testing.T) {
+	rl, _ := New(100, time.Hour)
+	for i := 0; i < sizeMinSamples-1; i++ {
+		if !rl.AllowBySize("key", uint64(1+i*1000), 50) {
+			t.Fatalf("expected nothing to be blocked before [%d] samples", sizeMinSamples)
+		}
+	}
+}
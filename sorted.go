@@ -0,0 +1,39 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortedEntries returns every key and count in the cache ordered by the key formatted with fmt,
+// so the result is the same for the same contents however they got there. Keys that format the
+// same, like 1 and "1", are ordered by type name. This is mostly for making tests deterministic.
+func (c *Cache) SortedEntries() []KeyCount {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return nil
+	}
+	type sortable struct {
+		kc       KeyCount
+		str, typ string
+	}
+	entries := make([]sortable, 0, c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		entries = append(entries, sortable{KeyCount{e.key, e.value}, fmt.Sprint(e.key), fmt.Sprintf("%T", e.key)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].str != entries[j].str {
+			return entries[i].str < entries[j].str
+		}
+		return entries[i].typ < entries[j].typ
+	})
+
+	sorted := make([]KeyCount, len(entries))
+	for i, s := range entries {
+		sorted[i] = s.kc
+	}
+	return sorted
+}
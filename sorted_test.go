@@ -0,0 +1,36 @@
+package ratelimiter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortedEntries(t *testing.T) {
+	fill := func(keys []interface{}) *Cache {
+		rl, _ := New(10, time.Hour)
+		for i, key := range keys {
+			for j := 0; j <= i%2; j++ {
+				_, _ = rl.Incr(key, 10)
+			}
+		}
+		return rl
+	}
+
+	rl := fill([]interface{}{"foo", "bar", 1, "1", "baz"})
+	want := []KeyCount{{1, 1}, {"1", 2}, {"bar", 2}, {"baz", 1}, {"foo", 1}}
+
+	first := rl.SortedEntries()
+	if !reflect.DeepEqual(first, want) {
+		t.Fatalf("expected sorted entries %v got %v", want, first)
+	}
+
+	// touching keys changes recency but not the sorted order
+	_, _ = rl.Get("foo")
+	_, _ = rl.Get(1)
+	for i := 0; i < 5; i++ {
+		if again := rl.SortedEntries(); !reflect.DeepEqual(again, first) {
+			t.Fatalf("expected the same order on every call, got %v then %v", first, again)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatsDSink is where StatsD metric lines are sent, e.g. a thin wrapper around a UDP connection
+// or an existing StatsD client, so the cache doesn't depend on any particular one.
+type StatsDSink interface {
+	Send(line string) error
+}
+
+// StatsDConfig controls what ExportStatsD sends. The cache wide gauges are always sent, per key
+// gauges only with PerKey, and SampleRate and MaxKeys keep those from flooding StatsD when there
+// are a lot of keys.
+type StatsDConfig struct {
+
+	// Prefix is put in front of every metric name, e.g. "api.ratelimit."
+	Prefix string
+
+	// PerKey sends a gauge for each key's count as well as the cache wide ones
+	PerKey bool
+
+	// SampleRate is the fraction of keys that are considered for per key gauges, chosen by hash
+	// so the same keys are sent every time. Zero means every key.
+	SampleRate float64
+
+	// MaxKeys caps the per key gauges to the keys with the highest counts. Zero means metricsTopKeys.
+	MaxKeys int
+}
+
+// statsdReplacer swaps out the characters that mean something in a StatsD line
+var statsdReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", " ", "_", "\n", "_")
+
+// ExportStatsD sends the cache's Metrics to sink as StatsD gauges, stopping at the first error.
+// The cache wide gauges are size, capacity, evictions and violations, and per key gauges are named
+// keys. followed by the key formatted with fmt.
+func (c *Cache) ExportStatsD(sink StatsDSink, config StatsDConfig) error {
+	m := c.Metrics()
+	lines := []string{
+		fmt.Sprintf("%ssize:%d|g", config.Prefix, m.Size),
+		fmt.Sprintf("%scapacity:%d|g", config.Prefix, m.Capacity),
+		fmt.Sprintf("%sevictions:%d|g", config.Prefix, m.Evictions),
+		fmt.Sprintf("%sviolations:%d|g", config.Prefix, m.Violations),
+	}
+
+	if config.PerKey {
+		maxKeys := config.MaxKeys
+		if maxKeys <= 0 {
+			maxKeys = metricsTopKeys
+		}
+		for _, k := range c.statsdKeys(config.SampleRate, maxKeys) {
+			lines = append(lines, fmt.Sprintf("%skeys.%s:%d|g", config.Prefix, statsdReplacer.Replace(k.Key), k.Count))
+		}
+	}
+
+	for _, line := range lines {
+		if err := sink.Send(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statsdKeys returns up to maxKeys of the highest count keys out of the sampled ones
+func (c *Cache) statsdKeys(sampleRate float64, maxKeys int) []MetricsKey {
+	c.lock.RLock()
+	keys := make([]MetricsKey, 0, c.evictList.Len())
+	for ent := c.evictList.Front(); ent != nil && !c.closed; ent = ent.Next() {
+		e := ent.Value.(*entry)
+		if sampleRate > 0 && sampleRate < 1 && float64(hashKey(e.key)%1000000) >= sampleRate*1000000 {
+			continue
+		}
+		keys = append(keys, MetricsKey{fmt.Sprint(e.key), e.value})
+	}
+	c.lock.RUnlock()
+
+	sortMetricsKeys(keys)
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+	}
+	return keys
+}
+
+// StartStatsDExport starts a background goroutine that calls ExportStatsD every interval.
+// Send errors are ignored. It does nothing if an export is already running.
+func (c *Cache) StartStatsDExport(interval time.Duration, sink StatsDSink, config StatsDConfig) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || c.statsdStop != nil || interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	c.statsdStop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = c.ExportStatsD(sink, config)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopStatsDExport stops the background goroutine started by StartStatsDExport, it's safe
+// to call when no export is running.
+func (c *Cache) StopStatsDExport() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.stopStatsDExport()
+}
+
+// stopStatsDExport is StopStatsDExport for callers that already hold the write lock
+func (c *Cache) stopStatsDExport() {
+	if c.statsdStop != nil {
+		close(c.statsdStop)
+		c.statsdStop = nil
+	}
+}
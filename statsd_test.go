@@ -0,0 +1,88 @@
+package ratelimiter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeStatsD records every line it's sent and fails once err is set
+type fakeStatsD struct {
+	lines []string
+	err   error
+}
+
+func (s *fakeStatsD) Send(line string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+func TestExportStatsD(t *testing.T) {
+	rl, _ := New(3, time.Hour)
+	rl.Incr("foo", 10)
+	rl.Incr("bar", 10)
+	rl.Incr("bar", 10)
+	rl.Incr("baz:qux", 10)
+	rl.Incr("quux", 10)
+
+	sink := &fakeStatsD{}
+	if err := rl.ExportStatsD(sink, StatsDConfig{Prefix: "rl.", PerKey: true}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// foo was evicted and the colon can't appear in a name
+	expected := []string{
+		"rl.size:3|g",
+		"rl.capacity:3|g",
+		"rl.evictions:1|g",
+		"rl.violations:0|g",
+		"rl.keys.bar:2|g",
+		"rl.keys.quux:1|g",
+		"rl.keys.baz_qux:1|g",
+	}
+	if !reflect.DeepEqual(sink.lines, expected) {
+		t.Fatalf("expected lines %v, got %v", expected, sink.lines)
+	}
+
+	sink = &fakeStatsD{}
+	_ = rl.ExportStatsD(sink, StatsDConfig{PerKey: true, MaxKeys: 1})
+	if len(sink.lines) != 5 || sink.lines[4] != "keys.bar:2|g" {
+		t.Fatalf("expected MaxKeys to keep the highest count, got %v", sink.lines)
+	}
+
+	sink = &fakeStatsD{}
+	_ = rl.ExportStatsD(sink, StatsDConfig{})
+	if len(sink.lines) != 4 {
+		t.Fatalf("expected only the cache wide gauges without PerKey, got %v", sink.lines)
+	}
+
+	sink.err = errors.New("Sink unavailable")
+	if err := rl.ExportStatsD(sink, StatsDConfig{}); err != sink.err {
+		t.Fatalf("expected the sink's error to be returned, got %v", err)
+	}
+}
+
+func TestExportStatsDSampling(t *testing.T) {
+	rl, _ := New(1000, time.Hour)
+	for i := 0; i < 1000; i++ {
+		rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+
+	config := StatsDConfig{PerKey: true, SampleRate: 0.1, MaxKeys: 1000}
+	first, second := &fakeStatsD{}, &fakeStatsD{}
+	_ = rl.ExportStatsD(first, config)
+	_ = rl.ExportStatsD(second, config)
+
+	keys := len(first.lines) - 4
+	if keys < 50 || keys > 150 {
+		t.Fatalf("expected about [100] sampled keys, got [%d]", keys)
+	}
+	if !reflect.DeepEqual(first.lines, second.lines) {
+		t.Fatalf("expected the same keys to be sampled each time")
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+// Store is a backend that keeps counts somewhere other than the cache's own memory, e.g. shared
+// between processes. Incr has the same meaning as Cache.Incr, err is for when the backend can't
+// be reached or can't answer.
+type Store interface {
+	Incr(key interface{}, maxValue int) (value uint64, underLimit bool, err error)
+}
+
+// DegradeMode decides what Incr reports when the Store behind the cache returns an error
+type DegradeMode int
+
+const (
+	// FailOpen is the default, the increment is allowed so an outage doesn't take the request path down with it
+	FailOpen DegradeMode = iota
+
+	// FailClosed denies the increment, for limits that protect something that can't take the extra load
+	FailClosed
+)
+
+// WithStore makes Incr go to store rather than the in memory entries. The rest of the cache's
+// methods still work on the in memory entries.
+func WithStore(store Store) Option {
+	return func(c *Cache) {
+		c.store = store
+	}
+}
+
+// WithDegradeMode sets what Incr reports when the Store returns an error, see DegradeMode.
+// The error itself is passed to OnStoreError if it's set.
+func WithDegradeMode(mode DegradeMode) Option {
+	return func(c *Cache) {
+		c.degradeMode = mode
+	}
+}
+
+// storeIncr is Incr when there's a Store, the store does its own locking so the cache's isn't held
+func (c *Cache) storeIncr(key interface{}, maxValue int) (uint64, bool) {
+	c.lock.RLock()
+	closed := c.closed
+	c.lock.RUnlock()
+	if key == nil || closed {
+		return 0, false
+	}
+
+	value, underLimit, err := c.store.Incr(key, maxValue)
+	if err == nil {
+		return value, underLimit
+	}
+
+	if c.OnStoreError != nil {
+		c.OnStoreError(key, err)
+	}
+	return 0, c.degradeMode == FailOpen
+}
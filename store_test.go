@@ -0,0 +1,50 @@
+package ratelimiter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeStore counts in a map and fails every call while err is set
+type fakeStore struct {
+	counts map[interface{}]uint64
+	err    error
+}
+
+func (s *fakeStore) Incr(key interface{}, maxValue int) (uint64, bool, error) {
+	if s.err != nil {
+		return 0, false, s.err
+	}
+	s.counts[key]++
+	return s.counts[key], s.counts[key] <= uint64(maxValue), nil
+}
+
+func TestStoreDegradeMode(t *testing.T) {
+	for _, mode := range []DegradeMode{FailOpen, FailClosed} {
+		store := &fakeStore{counts: make(map[interface{}]uint64)}
+		rl, _ := New(10, time.Hour, WithStore(store), WithDegradeMode(mode))
+
+		var errs int
+		rl.OnStoreError = func(key interface{}, err error) {
+			errs++
+		}
+
+		// while the store is up it makes the decision
+		rl.Incr("key", 1)
+		if _, ok := rl.Incr("key", 1); ok {
+			t.Fatalf("expected the store's count to block the key")
+		}
+		if store.counts["key"] != 2 || rl.Len() != 0 {
+			t.Fatalf("expected the increments to go to the store and not the cache")
+		}
+
+		store.err = errors.New("Store unavailable")
+		if _, ok := rl.Incr("key", 1); ok != (mode == FailOpen) {
+			t.Fatalf("expected degrade mode [%d] to allow [%t], got [%t]", mode, mode == FailOpen, ok)
+		}
+		if errs != 1 {
+			t.Fatalf("expected the error to be reported, have [%d]", errs)
+		}
+	}
+}
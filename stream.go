@@ -0,0 +1,104 @@
+package ratelimiter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// streamBuffer is how many events StreamEvents holds for a slow writer before dropping them
+const streamBuffer = 1024
+
+// StreamEventType is what happened to the entry in a StreamEvent
+type StreamEventType string
+
+// Event types written by StreamEvents
+const (
+	EventEvicted StreamEventType = "evicted"
+	EventExpired StreamEventType = "expired"
+	EventReset   StreamEventType = "reset"
+)
+
+// StreamEvent is one line written by StreamEvents. Key is formatted with fmt so it can be written
+// as JSON whatever its type, and Count is the key's count when it was evicted, expired or reset.
+type StreamEvent struct {
+	Type  StreamEventType `json:"type"`
+	Key   string          `json:"key"`
+	Count uint64          `json:"count"`
+	Time  time.Time       `json:"time"`
+}
+
+// eventStream buffers events for the goroutine writing them out
+type eventStream struct {
+	ch       chan StreamEvent
+	done     chan struct{}
+	overflow uint64
+}
+
+// stop writes out everything still buffered then waits for the writer goroutine to exit
+func (s *eventStream) stop() {
+	close(s.ch)
+	<-s.done
+}
+
+// StreamEvents starts a background goroutine that writes a line of JSON to w, see StreamEvent, every
+// time an entry is evicted for capacity, swept by the janitor once its rate period is over, or has its
+// count reset, whether by Reset or by its rate period rolling over. Events are buffered so a slow writer
+// never holds up the cache, once the buffer is full further events are dropped and counted, see
+// StreamOverflow. Write errors are ignored. It does nothing if a stream is already running.
+func (c *Cache) StreamEvents(w io.Writer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed || c.stream != nil {
+		return
+	}
+
+	s := &eventStream{ch: make(chan StreamEvent, streamBuffer), done: make(chan struct{})}
+	c.stream = s
+	go func() {
+		defer close(s.done)
+		enc := json.NewEncoder(w)
+		for ev := range s.ch {
+			_ = enc.Encode(ev)
+		}
+	}()
+}
+
+// StopStreamEvents writes out every event still buffered and stops the goroutine started by
+// StreamEvents, it's safe to call when no stream is running.
+func (c *Cache) StopStreamEvents() {
+	c.lock.Lock()
+	s := c.stream
+	c.stream = nil
+	c.lock.Unlock()
+
+	// a slow writer mustn't hold the lock while the buffer drains
+	if s != nil {
+		s.stop()
+	}
+}
+
+// StreamOverflow returns how many events StreamEvents has dropped because the writer fell behind.
+func (c *Cache) StreamOverflow() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.stream == nil {
+		return 0
+	}
+	return c.stream.overflow
+}
+
+// emit queues an event for the stream if there is one, callers must hold the write lock
+func (c *Cache) emit(typ StreamEventType, e *entry, count uint64) {
+	if c.stream == nil {
+		return
+	}
+	select {
+	case c.stream.ch <- StreamEvent{typ, fmt.Sprint(e.key), count, c.now()}:
+	default:
+		c.stream.overflow++
+	}
+}
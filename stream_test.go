@@ -0,0 +1,91 @@
+package ratelimiter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStreamEvents(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(2, 10*time.Second)
+	rl.now = clock.Now
+
+	buf := &lockedBuffer{}
+	rl.StreamEvents(buf)
+
+	rl.Incr("foo", 1)
+	rl.Incr("foo", 1)
+	rl.Incr("bar", 1)
+	rl.Incr("baz", 1) // evicts foo
+	rl.Reset("bar")
+	clock.Add(11 * time.Second)
+	rl.Incr("baz", 1) // rolls over baz's period
+	rl.Incr("baz", 1)
+	clock.Add(11 * time.Second)
+	rl.sweep() // expires both
+	rl.StopStreamEvents()
+
+	expected := []StreamEvent{
+		{EventEvicted, "foo", 2, time.Time{}},
+		{EventReset, "bar", 1, time.Time{}},
+		{EventReset, "baz", 1, time.Time{}},
+		{EventExpired, "bar", 0, time.Time{}},
+		{EventExpired, "baz", 2, time.Time{}},
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for i, want := range expected {
+		if !scanner.Scan() {
+			t.Fatalf("expected [%d] events, got [%d]", len(expected), i)
+		}
+		var got StreamEvent
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if got.Type != want.Type || got.Key != want.Key || got.Count != want.Count {
+			t.Fatalf("expected event [%d] to be %+v, got %+v", i, want, got)
+		}
+	}
+	if scanner.Scan() {
+		t.Fatalf("unexpected extra event %s", scanner.Text())
+	}
+}
+
+// blockingWriter holds up every write until it's released
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestStreamEventsOverflow(t *testing.T) {
+	rl, _ := New(1, time.Hour)
+	w := &blockingWriter{release: make(chan struct{})}
+	rl.StreamEvents(w)
+
+	// the writer is stuck, so at most one event is in flight and the rest overflow the buffer
+	n := streamBuffer + 100
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			rl.Incr(i, 10)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a stuck writer not to block Incr")
+	}
+
+	if overflow := rl.StreamOverflow(); overflow < 98 || overflow > 99 {
+		t.Fatalf("expected about [99] dropped events, got [%d]", overflow)
+	}
+	close(w.release)
+	rl.StopStreamEvents()
+}
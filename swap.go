@@ -0,0 +1,41 @@
+package ratelimiter
+
+// Swap sets key's count to newValue and returns the count it had before, atomically.
+// If the key isn't cached it's added with newValue and a fresh rate period, and existed is false.
+// The count isn't an increment, so the key's lifetime total is left alone.
+func (c *Cache) Swap(key interface{}, newValue uint64) (old uint64, existed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return 0, false
+	}
+
+	_, existed = c.find(key)
+	e := c.lookup(key)
+	old = e.value
+	e.value = newValue
+	return old, existed
+}
+
+// GetOrSet returns key's count in its current rate period, or if the key isn't cached or its rate
+// period is over, atomically sets it to def with a fresh rate period and returns def, with created true.
+// Like Swap the count isn't an increment, so the key's lifetime total is left alone.
+func (c *Cache) GetOrSet(key interface{}, def uint64) (value uint64, created bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return 0, false
+	}
+
+	_, existed := c.find(key)
+	e := c.lookup(key)
+	if now := c.now(); existed && c.expired(e, now) {
+		c.rollover(e, key, e.value, now)
+	} else if existed {
+		return e.value, false
+	}
+	e.value = def
+	return def, true
+}
@@ -0,0 +1,99 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwap(t *testing.T) {
+	rl, _ := New(10, 10*time.Second)
+
+	key := "foo"
+	for i := 0; i < 5; i++ {
+		_, _ = rl.Incr(key, 10)
+	}
+
+	old, existed := rl.Swap(key, 42)
+	if !existed {
+		t.Fatalf("expected foo to exist before the swap")
+	}
+	if old != 5 {
+		t.Fatalf("expected an old value of [5] but got [%d]", old)
+	}
+	if cnt, _ := rl.Get(key); cnt != 42 {
+		t.Fatalf("expected foo to hold the swapped value [42] but got [%d]", cnt)
+	}
+
+	old, existed = rl.Swap("bar", 7)
+	if existed {
+		t.Fatalf("expected bar not to exist before the swap")
+	}
+	if old != 0 {
+		t.Fatalf("expected a missing key to report an old value of [0] but got [%d]", old)
+	}
+	if cnt, ok := rl.Get("bar"); !ok || cnt != 7 {
+		t.Fatalf("expected bar to be created with [7] but got [%d]", cnt)
+	}
+
+	// the swapped value is what the next increment builds on
+	if cnt, _ := rl.Incr("bar", 10); cnt != 8 {
+		t.Fatalf("expected incrementing bar to give [8] but got [%d]", cnt)
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, 10*time.Second)
+	rl.now = clock.Now
+
+	// absent
+	if value, created := rl.GetOrSet("key", 5); value != 5 || !created {
+		t.Fatalf("expected the default for an absent key, got [%d] [%t]", value, created)
+	}
+
+	// present and fresh
+	rl.Incr("key", 10)
+	if value, created := rl.GetOrSet("key", 5); value != 6 || created {
+		t.Fatalf("expected the existing count, got [%d] [%t]", value, created)
+	}
+
+	// present but expired
+	clock.Add(11 * time.Second)
+	if value, created := rl.GetOrSet("key", 2); value != 2 || !created {
+		t.Fatalf("expected the default once the rate period is over, got [%d] [%t]", value, created)
+	}
+	if value, created := rl.GetOrSet("key", 5); value != 2 || created {
+		t.Fatalf("expected the default to start a fresh period, got [%d] [%t]", value, created)
+	}
+	if total, _ := rl.Lifetime("key"); total != 1 {
+		t.Fatalf("expected only the Incr in the lifetime total, got [%d]", total)
+	}
+}
+
+// an expired GetOrSet starts the period over the same way Incr does
+func TestGetOrSetRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Minute)
+	rl.now = clock.Now
+
+	var audited int
+	rl.OnAudit = func(record AuditRecord) {
+		audited++
+	}
+	rl.Incr("k", 5)
+	rl.MergeCRDT("b", []KeyCount{{"k", 9}})
+
+	clock.Add(2 * time.Minute)
+	if value, created := rl.GetOrSet("k", 0); value != 0 || !created {
+		t.Fatalf("expected the expired key to be set again, got [%d] [%t]", value, created)
+	}
+	if count, _ := rl.MergedCount("k"); count != 0 {
+		t.Fatalf("expected the merged counts to go with the old period, got [%d]", count)
+	}
+	if _, ok := rl.Incr("k", 5); !ok {
+		t.Fatalf("expected Incr to be allowed in the new period")
+	}
+	if audited != 1 || rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be reported, got [%d] audits", audited)
+	}
+}
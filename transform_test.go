@@ -0,0 +1,57 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnEvictedValue(t *testing.T) {
+	rl, _ := New(1, time.Hour)
+
+	var got interface{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		got = value
+	}
+	for i := 0; i < 3; i++ {
+		rl.Incr("foo", 10)
+	}
+	rl.Incr("bar", 10)
+	if got != uint64(3) {
+		t.Fatalf("expected the raw count [3] by default, got [%v]", got)
+	}
+}
+
+func TestValueTransformer(t *testing.T) {
+	rl, _ := New(1, time.Hour)
+
+	type bucketed struct {
+		key    interface{}
+		bucket string
+	}
+	rl.ValueTransformer = func(key interface{}, value uint64) interface{} {
+		if value >= 5 {
+			return bucketed{key, "heavy"}
+		}
+		return bucketed{key, "light"}
+	}
+	var got interface{}
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		got = value
+	}
+
+	for i := 0; i < 7; i++ {
+		rl.Incr("foo", 10)
+	}
+	if value, _ := rl.Peek("foo"); value != 7 {
+		t.Fatalf("expected the cache to keep the raw count [7], got [%d]", value)
+	}
+
+	rl.Incr("bar", 10)
+	if expected := (bucketed{"foo", "heavy"}); got != expected {
+		t.Fatalf("expected OnEvicted to get %v, got %v", expected, got)
+	}
+	rl.Remove("bar")
+	if expected := (bucketed{"bar", "light"}); got != expected {
+		t.Fatalf("expected OnEvicted to get %v, got %v", expected, got)
+	}
+}
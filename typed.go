@@ -0,0 +1,171 @@
+package ratelimiter
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Number is the set of counter types a TypedCache can count in
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// TypedCache is a smaller counterpart to Cache with a key type K and a counter type N of the caller's
+// choosing, e.g. uint32 counters to save memory or float64 ones for fractional costs. It has the same
+// LRU eviction and rate period as Cache but none of the optional features, Cache remains the uint64
+// counter cache for everything else. It is safe for concurrent access.
+type TypedCache[K comparable, N Number] struct {
+
+	// MaxEntries is the maximum number of cache entries before
+	// an item is evicted.
+	MaxEntries int
+
+	// OnEvicted optionally specificies a callback function to be
+	// executed when an entry is purged from the cache.
+	OnEvicted func(key K, value N)
+
+	ratePeriod time.Duration
+	evictList  *list.List
+	cache      map[K]*list.Element
+	lock       sync.Mutex
+	now        func() time.Time
+	closed     bool
+}
+
+type typedEntry[K comparable, N Number] struct {
+	key     K
+	value   N
+	updated time.Time
+}
+
+// NewTyped creates a new TypedCache, the same as New does a Cache.
+func NewTyped[K comparable, N Number](maxEntries int, ratePeriod time.Duration) (*TypedCache[K, N], error) {
+	if maxEntries <= 0 {
+		return nil, errors.New("Must provide a positive size")
+	}
+	return &TypedCache[K, N]{
+		MaxEntries: maxEntries,
+		ratePeriod: ratePeriod,
+		evictList:  list.New(),
+		cache:      make(map[K]*list.Element),
+		now:        timeNow,
+	}, nil
+}
+
+// Incr adds 1 to key's count, see IncrBy.
+func (c *TypedCache[K, N]) Incr(key K, maxValue N) (N, bool) {
+	return c.IncrBy(key, 1, maxValue)
+}
+
+// IncrBy adds cost to key's count and reports whether it's still at or under maxValue, starting the
+// count over at cost if it's over and the rate period is up, the same as Cache.Incr. Like Cache.Incr
+// the increment that starts a count, for a new key or a new rate period, is always let through.
+// Integer counts never wrap around, an increment that would overflow N leaves the count where it was
+// and is reported as over the limit until the rate period is up. A nil key or a closed cache is reported as over the limit.
+func (c *TypedCache[K, N]) IncrBy(key K, cost, maxValue N) (N, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if any(key) == nil || c.closed {
+		return 0, false
+	}
+
+	now := c.now()
+	ee, ok := c.cache[key]
+	if !ok {
+		if c.evictList.Len() >= c.MaxEntries {
+			c.removeOldest()
+		}
+		e := &typedEntry[K, N]{key: key, value: cost, updated: now}
+		c.cache[key] = c.evictList.PushFront(e)
+		return e.value, true
+	}
+
+	c.evictList.MoveToFront(ee)
+	e := ee.Value.(*typedEntry[K, N])
+	sum := e.value + cost
+	overflow := (cost > 0 && sum < e.value) || (cost < 0 && sum > e.value)
+	if !overflow && sum <= maxValue {
+		e.value = sum
+		return e.value, true
+	}
+
+	// an overflowing count mustn't keep the key from starting over once the period is up
+	if c.ratePeriod > 0 && now.Sub(e.updated) > c.ratePeriod {
+		e.value = cost
+		e.updated = now
+		return e.value, true
+	}
+	if !overflow {
+		e.value = sum
+	}
+	return e.value, false
+}
+
+// Get looks up a key's value from the cache.
+func (c *TypedCache[K, N]) Get(key K) (value N, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.cache[key]; ok {
+		c.evictList.MoveToFront(ent)
+		return ent.Value.(*typedEntry[K, N]).value, true
+	}
+	return
+}
+
+// Remove removes the provided key from the cache.
+func (c *TypedCache[K, N]) Remove(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return
+	}
+	if ent, ok := c.cache[key]; ok {
+		c.removeElement(ent)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *TypedCache[K, N]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return 0
+	}
+	return c.evictList.Len()
+}
+
+// Close marks the cache closed, after which IncrBy reports every key as over the limit, Get reports
+// every key as missing, Remove does nothing and Len is 0. Closing again does nothing.
+func (c *TypedCache[K, N]) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+func (c *TypedCache[K, N]) removeOldest() {
+	if ent := c.evictList.Back(); ent != nil {
+		c.removeElement(ent)
+	}
+}
+
+func (c *TypedCache[K, N]) removeElement(ent *list.Element) {
+	c.evictList.Remove(ent)
+	e := ent.Value.(*typedEntry[K, N])
+	delete(c.cache, e.key)
+	if c.OnEvicted != nil {
+		c.OnEvicted(e.key, e.value)
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimiter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTypedCacheUint32(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := NewTyped[string, uint32](2, 10*time.Second)
+	rl.now = clock.Now
+
+	for i := uint32(1); i <= 3; i++ {
+		if value, ok := rl.Incr("foo", 2); value != i || ok != (i <= 2) {
+			t.Fatalf("expected increment [%d] to be allowed [%t], got [%d] [%t]", i, i <= 2, value, ok)
+		}
+	}
+	clock.Add(11 * time.Second)
+	if value, ok := rl.Incr("foo", 2); value != 1 || !ok {
+		t.Fatalf("expected the count to start over after the rate period, got [%d]", value)
+	}
+
+	// a count near the top of uint32 doesn't wrap around to a small one and slip under the limit
+	rl.IncrBy("big", math.MaxUint32-1, math.MaxUint32)
+	if value, ok := rl.IncrBy("big", 5, math.MaxUint32); value != math.MaxUint32-1 || ok {
+		t.Fatalf("expected the overflowing increment to be refused, got [%d] [%t]", value, ok)
+	}
+
+	var evicted []string
+	rl.OnEvicted = func(key string, value uint32) {
+		evicted = append(evicted, key)
+	}
+	rl.Incr("bar", 2)
+	if len(evicted) != 1 || evicted[0] != "foo" || rl.Len() != 2 {
+		t.Fatalf("expected the least recently used key to be evicted, got %v", evicted)
+	}
+}
+
+func TestTypedCacheFloat64(t *testing.T) {
+	rl, _ := NewTyped[int, float64](10, time.Hour)
+
+	// fractional costs add up against a fractional limit
+	for i := 0; i < 4; i++ {
+		if _, ok := rl.IncrBy(1, 0.25, 1.1); !ok {
+			t.Fatalf("expected cost [%f] to be under the limit", float64(i+1)*0.25)
+		}
+	}
+	if value, ok := rl.IncrBy(1, 0.25, 1.1); ok || value != 1.25 {
+		t.Fatalf("expected [1.25] to be over the limit, got [%f] [%t]", value, ok)
+	}
+	if value, ok := rl.Get(1); !ok || value != 1.25 {
+		t.Fatalf("expected Get to return the fractional count, got [%f]", value)
+	}
+	rl.Remove(1)
+	if _, ok := rl.Get(1); ok {
+		t.Fatalf("expected the key to be removed")
+	}
+}
+
+func TestTypedCacheMatchesIncr(t *testing.T) {
+	rl, _ := NewTyped[interface{}, int](10, time.Hour)
+
+	// a new key is let through whatever the limit, the same as Cache.Incr
+	if value, ok := rl.Incr("foo", 0); value != 1 || !ok {
+		t.Fatalf("expected a new key to be allowed, got [%d] [%t]", value, ok)
+	}
+	if _, ok := rl.Incr(nil, 10); ok || rl.Len() != 1 {
+		t.Fatalf("expected a nil key to be refused")
+	}
+
+	if err := rl.Close(); err != nil {
+		t.Fatalf("expected Close to succeed, got %v", err)
+	}
+	if _, ok := rl.Incr("bar", 10); ok {
+		t.Fatalf("expected Incr on a closed cache to be rejected")
+	}
+	if _, ok := rl.Get("foo"); ok || rl.Len() != 0 {
+		t.Fatalf("expected a closed cache to report itself empty")
+	}
+}
+
+// a key blocked long enough to reach the top of N still starts over once the period is up
+func TestTypedCacheSaturatedRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := NewTyped[string, uint8](10, time.Minute)
+	rl.now = clock.Now
+
+	for i := 0; i < 300; i++ {
+		rl.Incr("foo", 10)
+	}
+	if value, ok := rl.Incr("foo", 10); value != math.MaxUint8 || ok {
+		t.Fatalf("expected the count to stop at the top of uint8, got [%d] [%t]", value, ok)
+	}
+
+	clock.Add(10 * time.Minute)
+	if value, ok := rl.Incr("foo", 10); value != 1 || !ok {
+		t.Fatalf("expected the count to start over after the rate period, got [%d] [%t]", value, ok)
+	}
+}
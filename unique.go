@@ -0,0 +1,119 @@
+package ratelimiter
+
+import "math"
+
+const (
+	// bits of filter per distinct item allowed, which keeps the estimate within a few percent
+	// up to the limit
+	bloomBitsPerItem = 16
+
+	// hashes set per item
+	bloomHashes = 4
+
+	// distinct items the filter is sized for when there's no WithUniqueLimit
+	defaultUniqueItems = 1024
+)
+
+// bloom is a fixed size bloom filter that also estimates how many distinct items it holds
+type bloom struct {
+	bits []uint64
+	set  int
+}
+
+func newBloom(n int) *bloom {
+	words := (n*bloomBitsPerItem + 63) / 64
+	if words < 1 {
+		words = 1
+	}
+	return &bloom{bits: make([]uint64, words)}
+}
+
+// add sets the bits for item
+func (b *bloom) add(item interface{}) {
+	b.each(item, func(word int, mask uint64) bool {
+		if b.bits[word]&mask == 0 {
+			b.bits[word] |= mask
+			b.set++
+		}
+		return true
+	})
+}
+
+// has reports whether every bit for item is set, meaning it was probably added
+func (b *bloom) has(item interface{}) bool {
+	found := true
+	b.each(item, func(word int, mask uint64) bool {
+		found = b.bits[word]&mask != 0
+		return found
+	})
+	return found
+}
+
+// each calls fn with the word and mask of every bit for item until fn returns false
+func (b *bloom) each(item interface{}, fn func(word int, mask uint64) bool) {
+	sum := hashKey(item)
+	h1, h2 := sum&0xffffffff, sum>>32|1
+	m := uint64(len(b.bits) * 64)
+
+	for i := uint64(0); i < bloomHashes; i++ {
+		bit := (h1 + i*h2) % m
+		if !fn(int(bit/64), uint64(1)<<(bit%64)) {
+			return
+		}
+	}
+}
+
+// estimate returns the approximate number of distinct items added from how full the filter is
+func (b *bloom) estimate() uint64 {
+	m := float64(len(b.bits) * 64)
+	if b.set >= int(m) {
+		return uint64(m)
+	}
+	return uint64(math.Round(-m / bloomHashes * math.Log(1-float64(b.set)/m)))
+}
+
+// WithUniqueLimit sets how many distinct items a key can add with AddUnique in a rate period.
+// The filter holding them is a fixed size based on maxDistinct, so memory per key stays the same
+// however many items are added. Without it, or with a maxDistinct of 0 or less, there's no limit and
+// the filter is sized for estimates up to 1024 items.
+func WithUniqueLimit(maxDistinct int) Option {
+	return func(c *Cache) {
+		c.uniqueLimit = maxDistinct
+	}
+}
+
+// AddUnique records item against key and returns an estimate of how many distinct items key has
+// added this rate period and whether that's still within the WithUniqueLimit limit, always true if
+// there's no limit. It's the bounded memory counterpart to IncrDistinctChild: items go into a bloom
+// filter rather than a set, so the estimate can be off by a few percent and, rarely, a new item may
+// be taken for one already seen.
+// The filter is cleared when the key's rate period is over, which is shared with Incr on the same key.
+func (c *Cache) AddUnique(key, item interface{}) (estimatedDistinct uint64, underLimit bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if key == nil || c.closed {
+		return 0, false
+	}
+
+	e, ok := c.admit(key)
+	if !ok {
+		return 0, false
+	}
+	if now := c.now(); c.expired(e, now) {
+		c.rollover(e, key, e.value, now)
+		e.value = 0
+	}
+
+	if e.unique == nil {
+		size := c.uniqueLimit
+		if size <= 0 {
+			size = defaultUniqueItems
+		}
+		e.unique = newBloom(size)
+	}
+	e.unique.add(item)
+
+	estimatedDistinct = e.unique.estimate()
+	return estimatedDistinct, c.uniqueLimit <= 0 || estimatedDistinct <= uint64(c.uniqueLimit)
+}
@@ -0,0 +1,96 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAddUniqueEstimate(t *testing.T) {
+	maxDistinct := 1000
+	rl, _ := New(10, time.Hour, WithUniqueLimit(maxDistinct))
+
+	for _, n := range []int{10, 100, 500, 1000} {
+		key := fmt.Sprintf("key_%d", n)
+		var estimate uint64
+		for i := 0; i < n; i++ {
+			estimate, _ = rl.AddUnique(key, i)
+		}
+		// adding the same items again doesn't move the estimate
+		for i := 0; i < n; i++ {
+			if again, _ := rl.AddUnique(key, i); again != estimate {
+				t.Fatalf("expected a repeated item to leave the estimate at [%d], got [%d]", estimate, again)
+			}
+		}
+
+		if diff := float64(estimate) - float64(n); diff > 0.05*float64(n)+1 || diff < -0.05*float64(n)-1 {
+			t.Fatalf("expected an estimate within 5%% of [%d], got [%d]", n, estimate)
+		}
+	}
+}
+
+func TestAddUniqueLimit(t *testing.T) {
+	clock := newFakeClock()
+	maxDistinct := 100
+	rl, _ := New(10, 10*time.Second, WithUniqueLimit(maxDistinct))
+	rl.now = clock.Now
+
+	// keep well clear of the limit either way to stay out of the filter's error
+	for i := 0; i < 90; i++ {
+		if _, ok := rl.AddUnique("key", i); !ok {
+			t.Fatalf("expected item [%d] to be under the limit", i+1)
+		}
+	}
+	var blocked bool
+	for i := 90; i < 120; i++ {
+		_, ok := rl.AddUnique("key", i)
+		blocked = !ok
+	}
+	if !blocked {
+		t.Fatalf("expected [120] distinct items to be over the limit of [%d]", maxDistinct)
+	}
+
+	clock.Add(11 * time.Second)
+	if estimate, ok := rl.AddUnique("key", "next"); !ok || estimate != 1 {
+		t.Fatalf("expected the filter to be cleared after the rate period, got [%d] [%t]", estimate, ok)
+	}
+}
+
+func TestAddUniqueWithoutLimit(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+
+	var estimate uint64
+	for i := 0; i < 500; i++ {
+		var ok bool
+		if estimate, ok = rl.AddUnique("key", i); !ok {
+			t.Fatalf("expected no limit without WithUniqueLimit, refused at item [%d]", i+1)
+		}
+	}
+	if estimate < 475 || estimate > 525 {
+		t.Fatalf("expected an estimate within 5%% of [500], got [%d]", estimate)
+	}
+}
+
+// AddUnique starting the shared period over doesn't carry a blocked Incr count into it
+func TestAddUniqueRollover(t *testing.T) {
+	clock := newFakeClock()
+	rl, _ := New(10, time.Minute)
+	rl.now = clock.Now
+
+	var audited int
+	rl.OnAudit = func(record AuditRecord) {
+		audited++
+	}
+	for i := 0; i < 5; i++ {
+		rl.Incr("key", 2)
+	}
+
+	clock.Add(2 * time.Minute)
+	rl.AddUnique("key", "item")
+	if _, ok := rl.Incr("key", 2); !ok {
+		t.Fatalf("expected Incr to be allowed in the period AddUnique started")
+	}
+	if audited != 1 || rl.CumulativeStats().Resets != 1 {
+		t.Fatalf("expected the rollover to be reported once, got [%d] audits", audited)
+	}
+}
@@ -0,0 +1,37 @@
+package ratelimiter
+
+import "time"
+
+// Violation describes a single Incr that put a key over its rate limit.
+// It carries enough context for alerting to judge severity without going back to the cache.
+type Violation struct {
+	Key   interface{}
+	Count uint64
+
+	// MaxValue is the limit that was in effect for the Incr
+	MaxValue int
+
+	// WindowStart is when the key's current rate period began
+	WindowStart time.Time
+
+	// RetryAfter is how long until the rate period lifts, zero means it never will
+	RetryAfter time.Duration
+
+	// Time is when the violation happened
+	Time time.Time
+}
+
+// newViolation builds the Violation payload for an entry that just went over maxValue
+func (c *Cache) newViolation(e *entry, maxValue int, now time.Time) Violation {
+	v := Violation{
+		Key:         e.key,
+		Count:       e.value,
+		MaxValue:    maxValue,
+		WindowStart: e.updated,
+		Time:        now,
+	}
+	if c.ratePeriod > 0 {
+		v.RetryAfter = c.windowEnd(e).Sub(now)
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestViolationPayload(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	rl, _ := New(100, 10*time.Second)
+	rl.now = func() time.Time { return now }
+
+	var violations []Violation
+	rl.OnViolation = func(v Violation) {
+		violations = append(violations, v)
+	}
+
+	key := "foo"
+	maxCount := 3
+	for i := 0; i < maxCount; i++ {
+		_, _ = rl.Incr(key, maxCount)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations while under the limit, got [%d]", len(violations))
+	}
+
+	now = start.Add(4 * time.Second)
+	_, _ = rl.Incr(key, maxCount)
+	if len(violations) != 1 {
+		t.Fatalf("expected exactly [1] violation, got [%d]", len(violations))
+	}
+
+	v := violations[0]
+	if v.Key.(string) != key {
+		t.Fatalf("expected violation key [%s] actual [%v]", key, v.Key)
+	}
+	if v.Count != 4 {
+		t.Fatalf("expected violation count [4] actual [%d]", v.Count)
+	}
+	if v.MaxValue != maxCount {
+		t.Fatalf("expected violation maxValue [%d] actual [%d]", maxCount, v.MaxValue)
+	}
+	if !v.WindowStart.Equal(start) {
+		t.Fatalf("expected violation window start [%s] actual [%s]", start, v.WindowStart)
+	}
+	if v.RetryAfter != 6*time.Second {
+		t.Fatalf("expected violation retryAfter [6s] actual [%s]", v.RetryAfter)
+	}
+	if !v.Time.Equal(now) {
+		t.Fatalf("expected violation time [%s] actual [%s]", now, v.Time)
+	}
+}
+
+// with no rate period the limit never lifts so there's nothing to retry after
+func TestViolationPayloadWithoutPeriod(t *testing.T) {
+	rl, _ := New(100, 0)
+
+	var got *Violation
+	rl.OnViolation = func(v Violation) {
+		got = &v
+	}
+
+	key := "foo"
+	for i := 0; i < 3; i++ {
+		_, _ = rl.Incr(key, 2)
+	}
+	if got == nil {
+		t.Fatalf("expected a violation to be reported")
+	}
+	if got.RetryAfter != 0 {
+		t.Fatalf("expected a zero retryAfter without a rate period, actual [%s]", got.RetryAfter)
+	}
+	if got.MaxValue != 2 || got.Count != 3 {
+		t.Fatalf("expected count [3] over max [2], actual count [%d] max [%d]", got.Count, got.MaxValue)
+	}
+}
@@ -0,0 +1,40 @@
+package ratelimiter
+
+import "time"
+
+// WithWarmup ramps up the limit for new keys, so a cold client can't use its full quota
+// straight away. A key's limit starts at floor times maxValue when it's added to the cache
+// and grows linearly to maxValue over the warmup duration. floor should be between 0 and 1.
+func WithWarmup(warmup time.Duration, floor float64) Option {
+	return func(c *Cache) {
+		if floor < 0 {
+			floor = 0
+		}
+		if floor > 1 {
+			floor = 1
+		}
+		c.warmup = warmup
+		c.warmupFloor = floor
+	}
+}
+
+// effectiveLimit returns the limit that applies to the entry right now given the maxValue
+// passed to Incr, taking any per key limit, adaptive limit and warmup into account
+func (c *Cache) effectiveLimit(e *entry, maxValue int) int {
+	if e.limit > 0 {
+		maxValue = e.limit
+	}
+	maxValue = c.adaptiveLimit(maxValue)
+	if c.warmup <= 0 || e.created.IsZero() {
+		return maxValue
+	}
+
+	age := c.now().Sub(e.created)
+	if age >= c.warmup {
+		return maxValue
+	}
+
+	floor := c.warmupFloor * float64(maxValue)
+	ramp := (float64(maxValue) - floor) * float64(age) / float64(c.warmup)
+	return int(floor + ramp)
+}
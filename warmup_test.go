@@ -0,0 +1,54 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// countUntilLimited increments key until it's rate limited and returns how many increments were allowed
+func countUntilLimited(rl *Cache, key interface{}, maxValue int) int {
+	allowed := 0
+	for i := 0; i < maxValue*2; i++ {
+		if _, ok := rl.Incr(key, maxValue); !ok {
+			break
+		}
+		allowed++
+	}
+	return allowed
+}
+
+func TestWarmup(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+
+	rl, _ := New(10, time.Hour, WithWarmup(10*time.Second, 0.2))
+	rl.now = func() time.Time { return now }
+
+	maxCount := 100
+
+	// right after creation only the floor is allowed
+	if allowed := countUntilLimited(rl, "foo", maxCount); allowed != 20 {
+		t.Fatalf("expected [20] increments right after creation but got [%d]", allowed)
+	}
+
+	// halfway through warmup the limit is halfway between the floor and max
+	_, _ = rl.Incr("bar", maxCount)
+	now = start.Add(5 * time.Second)
+	if allowed := countUntilLimited(rl, "bar", maxCount); allowed != 59 {
+		t.Fatalf("expected [59] more increments halfway through warmup but got [%d]", allowed)
+	}
+
+	// once warmed up the full limit applies
+	_, _ = rl.Incr("baz", maxCount)
+	now = start.Add(15 * time.Second)
+	if allowed := countUntilLimited(rl, "baz", maxCount); allowed != 99 {
+		t.Fatalf("expected [99] more increments after warmup but got [%d]", allowed)
+	}
+}
+
+func TestWithoutWarmup(t *testing.T) {
+	rl, _ := New(10, time.Hour)
+	if allowed := countUntilLimited(rl, "foo", 100); allowed != 100 {
+		t.Fatalf("expected the full [100] increments without warmup but got [%d]", allowed)
+	}
+}
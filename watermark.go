@@ -0,0 +1,19 @@
+package ratelimiter
+
+// WithWatermarks evicts in batches rather than one entry per insert. Once an insert takes the cache
+// to high entries the oldest are evicted in one pass until it's down to low, so the next high-low
+// inserts don't evict at all. The batch skips protected entries and never evicts the entry being
+// inserted, so it can stop short of low. high is capped at the cache size and low must be below
+// high, otherwise the option is ignored.
+func WithWatermarks(high, low int) Option {
+	return func(c *Cache) {
+		if high > c.MaxEntries {
+			high = c.MaxEntries
+		}
+		if low <= 0 || low >= high {
+			return
+		}
+		c.highWatermark = high
+		c.lowWatermark = low
+	}
+}
@@ -0,0 +1,136 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWatermarks(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithWatermarks(10, 7))
+
+	evicted := 0
+	rl.OnEvicted = func(key interface{}, value interface{}) {
+		evicted++
+	}
+
+	for i := 0; i < 9; i++ {
+		_, _ = rl.Incr(fmt.Sprintf("foo_%d", i), 10)
+	}
+	if evicted != 0 || rl.Len() != 9 {
+		t.Fatalf("expected no evictions below the high watermark, evicted [%d] with [%d] keys", evicted, rl.Len())
+	}
+
+	// the 10th key hits the high watermark and trims down to the low one in a single burst
+	_, _ = rl.Incr("foo_9", 10)
+	if evicted != 3 || rl.Len() != 7 {
+		t.Fatalf("expected [3] evictions down to [7] keys, evicted [%d] with [%d] keys", evicted, rl.Len())
+	}
+	for i := 0; i < 3; i++ {
+		if rl.Contains(fmt.Sprintf("foo_%d", i)) {
+			t.Fatalf("expected the oldest keys to be trimmed, foo_%d is still there", i)
+		}
+	}
+	if !rl.Contains("foo_9") {
+		t.Fatalf("expected the key that triggered the trim to be kept")
+	}
+
+	// the next couple of inserts have room without evicting
+	_, _ = rl.Incr("bar", 10)
+	_, _ = rl.Incr("baz", 10)
+	if evicted != 3 {
+		t.Fatalf("expected no evictions until the high watermark again, evicted [%d]", evicted)
+	}
+}
+
+func TestWouldEvictWithWatermarks(t *testing.T) {
+	for _, tc := range []struct {
+		filled  int
+		newKeys int
+	}{
+		{0, 9}, {0, 10}, {5, 5}, {7, 3}, {9, 1}, {8, 12}, {3, 30},
+	} {
+		rl, _ := New(10, time.Hour, WithWatermarks(10, 7))
+		for i := 0; i < tc.filled; i++ {
+			_, _ = rl.Incr(fmt.Sprintf("old_%d", i), 10)
+		}
+
+		predicted := rl.WouldEvict(tc.newKeys)
+		evicted := 0
+		rl.OnEvicted = func(key interface{}, value interface{}) {
+			if key.(string)[:4] == "old_" {
+				evicted++
+			}
+		}
+		for i := 0; i < tc.newKeys; i++ {
+			_, _ = rl.Incr(fmt.Sprintf("new_%d", i), 10)
+		}
+
+		if predicted != evicted {
+			t.Fatalf("with [%d] filled and [%d] new keys expected [%d] evictions, predicted [%d]", tc.filled, tc.newKeys, evicted, predicted)
+		}
+	}
+}
+
+func TestWatermarksIgnoredWhenInvalid(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithWatermarks(5, 5))
+	for i := 0; i < 10; i++ {
+		_, _ = rl.Incr(i, 10)
+	}
+	if rl.Len() != 10 {
+		t.Fatalf("expected invalid watermarks to be ignored, have [%d] keys", rl.Len())
+	}
+}
+
+// the batch mustn't fall back to evicting the entry it's inserting when the rest are protected
+func TestWatermarksProtected(t *testing.T) {
+	rl, _ := New(3, time.Hour, WithWatermarks(3, 1))
+	rl.Protect("a")
+	rl.Protect("b")
+	rl.Incr("a", 5)
+	rl.Incr("b", 5)
+
+	windowed, lifetime, ok := rl.IncrFull("c", 5)
+	if !ok || windowed != 1 || lifetime != 1 {
+		t.Fatalf("expected c to be counted, got [%d] [%d] [%t]", windowed, lifetime, ok)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if !rl.Contains(key) {
+			t.Fatalf("expected %s to stay cached, have %v", key, rl.Keys())
+		}
+	}
+}
+
+func TestWouldEvictWithWatermarksProtected(t *testing.T) {
+	for _, tc := range []struct {
+		filled    int
+		protected int
+		newKeys   int
+	}{
+		{9, 9, 1}, {9, 9, 5}, {10, 10, 3}, {10, 4, 15}, {8, 3, 12}, {6, 2, 1}, {9, 5, 30},
+	} {
+		rl, _ := New(10, time.Hour, WithWatermarks(10, 5))
+		for i := 0; i < tc.filled; i++ {
+			key := fmt.Sprintf("old_%d", i)
+			if i < tc.protected {
+				rl.Protect(key)
+			}
+			_, _ = rl.Incr(key, 10)
+		}
+
+		predicted := rl.WouldEvict(tc.newKeys)
+		evicted := 0
+		rl.OnEvicted = func(key interface{}, value interface{}) {
+			if key.(string)[:4] == "old_" {
+				evicted++
+			}
+		}
+		for i := 0; i < tc.newKeys; i++ {
+			_, _ = rl.Incr(fmt.Sprintf("new_%d", i), 10)
+		}
+
+		if predicted != evicted {
+			t.Fatalf("with [%d] filled, [%d] protected and [%d] new keys expected [%d] evictions, predicted [%d]", tc.filled, tc.protected, tc.newKeys, evicted, predicted)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ratelimiter
+
+import "time"
+
+// ZeroPeriodMode is what a ratePeriod of 0 means, since with no period a limit can never lift
+type ZeroPeriodMode int
+
+const (
+	// ZeroPeriodBlock is the default, once a key goes over its limit it stays over it for the life of
+	// the entry, until it's removed, reset or evicted
+	ZeroPeriodBlock ZeroPeriodMode = iota
+
+	// ZeroPeriodCount never limits, keys just count up forever and Incr always reports them
+	// as under the rate limit, for using the cache as a bounded set of plain counters
+	ZeroPeriodCount
+)
+
+// WithZeroPeriodMode sets what a ratePeriod of 0 means, it has no effect with a positive ratePeriod.
+func WithZeroPeriodMode(mode ZeroPeriodMode) Option {
+	return func(c *Cache) {
+		c.zeroPeriodMode = mode
+	}
+}
+
+// WithMinWindow guarantees every rate period lasts at least d from the moment it actually started,
+// whatever ratePeriod and WithGranularity say. Without it a key first incremented just before a
+// granular boundary can have its period lifted almost immediately.
+func WithMinWindow(d time.Duration) Option {
+	return func(c *Cache) {
+		c.minWindow = d
+	}
+}
+
+// expired reports whether the entry's rate period is over as of now
+func (c *Cache) expired(e *entry, now time.Time) bool {
+	if c.ratePeriod <= 0 || now.Sub(e.updated) <= c.ratePeriod {
+		return false
+	}
+	return c.minWindow <= 0 || now.Sub(e.started) >= c.minWindow
+}
+
+// windowEnd returns when the entry's rate period is over, it's only meaningful with a ratePeriod
+func (c *Cache) windowEnd(e *entry) time.Time {
+	end := e.updated.Add(c.ratePeriod)
+	if min := e.started.Add(c.minWindow); c.minWindow > 0 && min.After(end) {
+		return min
+	}
+	return end
+}
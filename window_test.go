@@ -0,0 +1,86 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+// a key created just before a granular boundary still gets the full minimum window
+func TestMinWindow(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(9 * time.Second)
+
+	rl, _ := New(10, 2*time.Second, WithGranularity(10*time.Second), WithMinWindow(5*time.Second))
+	rl.now = func() time.Time { return now }
+
+	key := "foo"
+	maxCount := 1
+	_, _ = rl.Incr(key, maxCount)
+
+	// the granular window started at 0s so the rate period alone would be over by now
+	now = start.Add(11 * time.Second)
+	if _, underRateLimit := rl.Incr(key, maxCount); underRateLimit {
+		t.Fatalf("expected foo to still be limited inside its minimum window")
+	}
+
+	now = start.Add(14 * time.Second)
+	if cnt, underRateLimit := rl.Incr(key, maxCount); !underRateLimit || cnt != 1 {
+		t.Fatalf("expected foo to reset once its minimum window was over, got count [%d]", cnt)
+	}
+}
+
+func TestWithoutMinWindow(t *testing.T) {
+	start := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(9 * time.Second)
+
+	rl, _ := New(10, 2*time.Second, WithGranularity(10*time.Second))
+	rl.now = func() time.Time { return now }
+
+	_, _ = rl.Incr("foo", 1)
+	now = start.Add(11 * time.Second)
+	if _, underRateLimit := rl.Incr("foo", 1); !underRateLimit {
+		t.Fatalf("expected foo to reset straight after the boundary without a minimum window")
+	}
+}
+
+func TestZeroPeriodBlock(t *testing.T) {
+	rl, _ := New(10, 0, WithZeroPeriodMode(ZeroPeriodBlock))
+
+	maxCount := 3
+	for i := 1; i <= 10; i++ {
+		_, underRateLimit := rl.Incr("foo", maxCount)
+		if underRateLimit != (i <= maxCount) {
+			t.Fatalf("expected increment [%d] under the limit to be [%t]", i, i <= maxCount)
+		}
+	}
+
+	// only a reset lifts it
+	rl.Reset("foo")
+	if cnt, underRateLimit := rl.Incr("foo", maxCount); !underRateLimit || cnt != 1 {
+		t.Fatalf("expected foo to be allowed again after a reset, got count [%d]", cnt)
+	}
+}
+
+func TestZeroPeriodCount(t *testing.T) {
+	rl, _ := New(10, 0, WithZeroPeriodMode(ZeroPeriodCount))
+	rl.OnViolation = func(v Violation) {
+		t.Fatalf("expected no violations when only counting, got count [%d]", v.Count)
+	}
+
+	for i := 1; i <= 10; i++ {
+		cnt, underRateLimit := rl.Incr("foo", 3)
+		if !underRateLimit || cnt != uint64(i) {
+			t.Fatalf("expected increment [%d] to count forever without limiting, got count [%d]", i, cnt)
+		}
+	}
+}
+
+// ZeroPeriodCount only changes what a zero period means
+func TestZeroPeriodModeWithPeriod(t *testing.T) {
+	rl, _ := New(10, time.Hour, WithZeroPeriodMode(ZeroPeriodCount))
+
+	_, _ = rl.Incr("foo", 1)
+	if _, underRateLimit := rl.Incr("foo", 1); underRateLimit {
+		t.Fatalf("expected a positive rate period to still limit")
+	}
+}